@@ -0,0 +1,8 @@
+data "githubipallowlist_ip_allow_list_entries" "vpn" {
+  name_regex = "^VPN egress"
+  is_active  = true
+}
+
+data "githubipallowlist_ip_allow_list_entries" "covering_office" {
+  contains = "192.0.2.10"
+}
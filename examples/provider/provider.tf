@@ -3,4 +3,29 @@ provider "githubipallowlist" {
   organization = "your-org-name"
   base_url     = "https://your-github-enterprise-instance.com/graphql"
   concurrency  = 2
+
+  # Reuse entries listed by `terraform plan` in a following `terraform apply`
+  cache_ttl = "10m"
+  cache_dir = ".terraform/githubipallowlist-cache"
+}
+
+# Authenticate as a GitHub App installation instead of using a personal access token
+provider "githubipallowlist" {
+  alias        = "app"
+  organization = "your-org-name"
+
+  app_auth {
+    id              = "123456"
+    installation_id = "12345678"
+    pem_file        = file("your-app.private-key.pem")
+  }
+}
+
+# Read a token from a command, running it again every 10 minutes
+provider "githubipallowlist" {
+  alias         = "command"
+  organization  = "your-org-name"
+  token_command = ["gh", "auth", "token"]
+
+  token_refresh_interval = "10m"
 }
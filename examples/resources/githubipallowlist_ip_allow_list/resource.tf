@@ -0,0 +1,12 @@
+resource "githubipallowlist_ip_allow_list" "example" {
+  entry {
+    allow_list_value = "1.2.3.4/32"
+    is_active        = true
+    name             = "VPN egress eu-west-1"
+  }
+
+  entry {
+    allow_list_value = "192.0.2.0/24"
+    is_active        = false
+  }
+}
@@ -0,0 +1,10 @@
+# Import by the GraphQL node ID of the entry
+terraform import githubipallowlist_ip_allow_list_entry.example IALE_kwDOABCDEF4AAYVQ
+
+# Import by the allow list value, which has to match exactly one entry of the configured owner
+terraform import githubipallowlist_ip_allow_list_entry.example 1.2.3.4/32
+
+# Import an entry of an organization, an enterprise or a GitHub App other than the provider's one
+terraform import githubipallowlist_ip_allow_list_entry.example organization/your-org-name/1.2.3.4/32
+terraform import githubipallowlist_ip_allow_list_entry.example enterprise/your-enterprise-name/IALE_kwDOABCDEF4AAYVQ
+terraform import githubipallowlist_ip_allow_list_entry.example app/your-app-node-id/198.51.100.0/24
@@ -0,0 +1,24 @@
+resource "githubipallowlist_ip_allow_list_entry" "example" {
+  is_active        = false
+  allow_list_value = "1.2.3.4/32"
+  name             = "VPN egress eu-west-1"
+}
+
+resource "githubipallowlist_ip_allow_list_entry" "other_organization" {
+  organization     = "your-other-org-name"
+  is_active        = true
+  allow_list_value = "192.0.2.0/24"
+}
+
+resource "githubipallowlist_ip_allow_list_entry" "ipv6" {
+  is_active        = true
+  allow_list_value = "2001:db8::/32"
+  name             = "IPv6 egress gateways"
+}
+
+resource "githubipallowlist_ip_allow_list_entry" "app" {
+  app              = "your-app-node-id"
+  is_active        = true
+  allow_list_value = "198.51.100.0/24"
+  name             = "App webhook workers"
+}
@@ -0,0 +1,4 @@
+resource "githubipallowlist_ip_allow_list_for_installed_apps_setting" "example" {
+  organization = "your-org-name"
+  enabled      = true
+}
@@ -0,0 +1,3 @@
+# Import the setting of an organization or an enterprise
+terraform import githubipallowlist_ip_allow_list_setting.example organization/your-org-name
+terraform import githubipallowlist_ip_allow_list_setting.example enterprise/your-enterprise-name
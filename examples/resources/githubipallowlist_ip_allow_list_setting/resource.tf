@@ -0,0 +1,3 @@
+resource "githubipallowlist_ip_allow_list_setting" "example" {
+  enabled = true
+}
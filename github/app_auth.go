@@ -0,0 +1,192 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// appJWTLifetime is below the maximum of 10 minutes accepted by GitHub, leaving a margin for clock drift.
+	appJWTLifetime = 9 * time.Minute
+	// appJWTBackdate makes a JWT valid even if GitHub's clock is behind.
+	appJWTBackdate = 60 * time.Second
+)
+
+// AppInstallationTokenSource is an oauth2.TokenSource exchanging a JWT signed with a GitHub App's private key for an installation access token.
+// Wrap it with oauth2.ReuseTokenSource to reuse a token until it is about to expire.
+type AppInstallationTokenSource struct {
+	ctx            context.Context
+	http           *http.Client
+	appID          string
+	installationID string
+	privateKey     *rsa.PrivateKey
+	restAPIURL     string
+	headers        map[string]string
+	now            func() time.Time
+}
+
+type appInstallationTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewAppAuthenticatedGitHubClient creates a new client authenticated as a GitHub App installation with given ClientOptions.
+// appID is the GitHub App's ID or client ID, and privateKeyPEM is the GitHub App's private key in PEM format.
+// Installation access tokens are requested from GitHub's REST API next to the GraphQL API set with WithGraphQLAPIURL, and refreshed before they expire.
+func NewAppAuthenticatedGitHubClient(ctx context.Context, appID string, installationID string, privateKeyPEM []byte, opts ...ClientOption) (*Client, error) {
+	options := newClientOptions(opts...)
+	tokenSource, err := NewAppInstallationTokenSource(ctx, appID, installationID, privateKeyPEM, restAPIURL(options.graphQLAPIURL), options.headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewGitHubClientWithTokenSource(ctx, tokenSource, opts...), nil
+}
+
+// NewAppInstallationTokenSource creates a token source for a GitHub App installation using GitHub's REST API at restAPIURL, e.g. https://api.github.com.
+// headers are added to token requests. Tokens are requested with ctx, so it has to outlive the token source.
+func NewAppInstallationTokenSource(ctx context.Context, appID string, installationID string, privateKeyPEM []byte, restAPIURL string, headers map[string]string) (*AppInstallationTokenSource, error) {
+	if appID == "" {
+		return nil, errors.New("GitHub App ID is empty")
+	}
+	if installationID == "" {
+		return nil, errors.New("GitHub App installation ID is empty")
+	}
+	privateKey, err := parseRSAPrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &AppInstallationTokenSource{
+		ctx:            ctx,
+		http:           http.DefaultClient,
+		appID:          appID,
+		installationID: installationID,
+		privateKey:     privateKey,
+		restAPIURL:     strings.TrimSuffix(restAPIURL, "/"),
+		headers:        headers,
+		now:            time.Now,
+	}, nil
+}
+
+// Token requests a new installation access token.
+func (s *AppInstallationTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := s.jwt()
+	if err != nil {
+		return nil, err
+	}
+
+	tokenURL := fmt.Sprintf("%s/app/installations/%s/access_tokens", s.restAPIURL, url.PathEscape(s.installationID))
+	ctx, cancel := context.WithTimeout(s.ctx, tokenRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "installation access token request error")
+	}
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	res, err := s.http.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "installation access token request error")
+	}
+	defer res.Body.Close()
+
+	resBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, newErrorWithStatusCode(res, nil, fmt.Sprintf("installation access token response body read error: %s", err.Error()))
+	}
+	if res.StatusCode >= 300 {
+		return nil, newErrorWithStatusCode(res, resBytes, fmt.Sprintf("GitHub installation access token response: %s", string(resBytes)))
+	}
+
+	tokenRes := new(appInstallationTokenResponse)
+	err = json.Unmarshal(resBytes, tokenRes)
+	if err != nil {
+		return nil, errors.Wrap(err, "installation access token response unmarshalling error")
+	}
+	if tokenRes.Token == "" {
+		return nil, errors.New("installation access token response without a token")
+	}
+
+	return &oauth2.Token{
+		AccessToken: tokenRes.Token,
+		TokenType:   "Bearer",
+		Expiry:      tokenRes.ExpiresAt,
+	}, nil
+}
+
+// jwt creates a JSON Web Token signed with RS256 authenticating as the GitHub App.
+func (s *AppInstallationTokenSource) jwt() (string, error) {
+	now := s.now()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", errors.Wrap(err, "JWT header marshalling error")
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-appJWTBackdate).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": s.appID,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "JWT claims marshalling error")
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", errors.Wrap(err, "JWT signing error")
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseRSAPrivateKey parses a PKCS #1 private key, which GitHub generates for GitHub Apps, or a PKCS #8 RSA private key.
+func parseRSAPrivateKey(privateKeyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, errors.New("GitHub App private key is not PEM encoded")
+	}
+
+	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err == nil {
+		return privateKey, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "GitHub App private key parsing error")
+	}
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("GitHub App private key is not an RSA key")
+	}
+	return privateKey, nil
+}
+
+// restAPIURL derives GitHub's REST API URL from its GraphQL API URL,
+// e.g. https://api.github.com for https://api.github.com/graphql and https://github.example.com/api/v3 for https://github.example.com/api/graphql.
+func restAPIURL(graphQLAPIURL string) string {
+	u := strings.TrimSuffix(strings.TrimSuffix(graphQLAPIURL, "/"), "/graphql")
+	if strings.HasSuffix(u, "/api") {
+		return u + "/v3"
+	}
+	return u
+}
@@ -0,0 +1,195 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const someGetOrganizationIDResponse = `{"data": {"organization": {"id": "some-organization-id"}}}`
+
+func TestNewAppAuthenticatedGitHubClientAuthenticatesWithInstallationAccessToken(t *testing.T) {
+	// given
+	privateKey, privateKeyPEM := someAppPrivateKey(t)
+	gitHubAPIMock, tokensIssued := serverIssuingInstallationAccessTokens(t, &privateKey.PublicKey, "some-app-id", "123", time.Hour)
+	client, err := NewAppAuthenticatedGitHubClient(context.TODO(), "some-app-id", "123", privateKeyPEM,
+		WithGraphQLAPIURL(gitHubAPIMock.URL+"/graphql"), WithHeaders(map[string]string{"User-Agent": "some agent"}))
+	assert.NoError(t, err)
+
+	// when
+	_, err = client.GetOrganizationID(context.TODO(), "some organization")
+	assert.NoError(t, err)
+	organizationID, err := client.GetOrganizationID(context.TODO(), "other organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "some-organization-id", organizationID)
+	assert.Equal(t, int64(1), tokensIssued.Load())
+}
+
+func TestNewAppAuthenticatedGitHubClientRefreshesExpiringInstallationAccessToken(t *testing.T) {
+	// given
+	privateKey, privateKeyPEM := someAppPrivateKey(t)
+	gitHubAPIMock, tokensIssued := serverIssuingInstallationAccessTokens(t, &privateKey.PublicKey, "some-app-id", "123", time.Second)
+	client, err := NewAppAuthenticatedGitHubClient(context.TODO(), "some-app-id", "123", privateKeyPEM, WithGraphQLAPIURL(gitHubAPIMock.URL+"/graphql"))
+	assert.NoError(t, err)
+
+	// when
+	_, err = client.GetOrganizationID(context.TODO(), "some organization")
+	assert.NoError(t, err)
+	_, err = client.GetOrganizationID(context.TODO(), "other organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), tokensIssued.Load())
+}
+
+func TestNewAppAuthenticatedGitHubClientWithFailingTokenEndpoint(t *testing.T) {
+	// given
+	_, privateKeyPEM := someAppPrivateKey(t)
+	gitHubAPIMock := serverReturningAnEmptyResponseWith(http.StatusUnauthorized)
+	client, err := NewAppAuthenticatedGitHubClient(context.TODO(), "some-app-id", "123", privateKeyPEM, WithGraphQLAPIURL(gitHubAPIMock.URL+"/graphql"))
+	assert.NoError(t, err)
+
+	// when
+	_, err = client.GetOrganizationID(context.TODO(), "some organization")
+
+	// then
+	var target ErrorWithStatusCode
+	assert.ErrorAs(t, err, &target)
+	assert.Equal(t, http.StatusUnauthorized, target.StatusCode)
+}
+
+func TestNewAppAuthenticatedGitHubClientWithInvalidParameters(t *testing.T) {
+	_, privateKeyPEM := someAppPrivateKey(t)
+	tests := []struct {
+		name           string
+		appID          string
+		installationID string
+		privateKeyPEM  []byte
+		expectedError  string
+	}{
+		{name: "missing app ID", installationID: "123", privateKeyPEM: privateKeyPEM, expectedError: "App ID is empty"},
+		{name: "missing installation ID", appID: "some-app-id", privateKeyPEM: privateKeyPEM, expectedError: "installation ID is empty"},
+		{name: "not a PEM", appID: "some-app-id", installationID: "123", privateKeyPEM: []byte("some key"), expectedError: "not PEM encoded"},
+		{name: "not a private key", appID: "some-app-id", installationID: "123", privateKeyPEM: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte("some key")}), expectedError: "private key parsing error"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// when
+			client, err := NewAppAuthenticatedGitHubClient(context.TODO(), test.appID, test.installationID, test.privateKeyPEM)
+
+			// then
+			assert.ErrorContains(t, err, test.expectedError)
+			assert.Nil(t, client)
+		})
+	}
+}
+
+func TestParseRSAPrivateKeyAcceptsPKCS8(t *testing.T) {
+	// given
+	privateKey, _ := someAppPrivateKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	assert.NoError(t, err)
+
+	// when
+	parsed, err := parseRSAPrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, privateKey.Equal(parsed))
+}
+
+func TestRestAPIURL(t *testing.T) {
+	tests := []struct {
+		graphQLAPIURL string
+		expected      string
+	}{
+		{graphQLAPIURL: "https://api.github.com/graphql", expected: "https://api.github.com"},
+		{graphQLAPIURL: "https://github.example.com/api/graphql", expected: "https://github.example.com/api/v3"},
+		{graphQLAPIURL: "https://github.example.com/api/graphql/", expected: "https://github.example.com/api/v3"},
+	}
+	for _, test := range tests {
+		t.Run(test.graphQLAPIURL, func(t *testing.T) {
+			assert.Equal(t, test.expected, restAPIURL(test.graphQLAPIURL))
+		})
+	}
+}
+
+func someAppPrivateKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return privateKey, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+}
+
+// serverIssuingInstallationAccessTokens stands in for GitHub's installation access token endpoint, which verifies a JWT signed by the GitHub App,
+// and GitHub's GraphQL API, which accepts only the latest token issued.
+func serverIssuingInstallationAccessTokens(t *testing.T, publicKey *rsa.PublicKey, appID string, installationID string, tokenLifetime time.Duration) (*httptest.Server, *atomic.Int64) {
+	var tokensIssued atomic.Int64
+	mux := http.NewServeMux()
+	mux.HandleFunc(fmt.Sprintf("/app/installations/%s/access_tokens", installationID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.NoError(t, verifyAppJWT(publicKey, appID, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")))
+		issued := tokensIssued.Add(1)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"token": "token-%d", "expires_at": %q}`, issued, time.Now().Add(tokenLifetime).UTC().Format(time.RFC3339))
+	})
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != fmt.Sprintf("Bearer token-%d", tokensIssued.Load()) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(someGetOrganizationIDResponse))
+	})
+	return httptest.NewServer(mux), &tokensIssued
+}
+
+func verifyAppJWT(publicKey *rsa.PublicKey, appID string, jwt string) error {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed JWT %q", jwt)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	err = rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature)
+	if err != nil {
+		return err
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	var claims struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}
+	err = json.Unmarshal(claimsJSON, &claims)
+	if err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	if claims.Issuer != appID || claims.IssuedAt > now || claims.ExpiresAt < now || claims.ExpiresAt-claims.IssuedAt > 600 {
+		return fmt.Errorf("invalid JWT claims %s", claimsJSON)
+	}
+	return nil
+}
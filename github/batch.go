@@ -0,0 +1,305 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+)
+
+// defaultMutationBatchSize keeps a batch well within GitHub's limits of a single GraphQL request.
+const defaultMutationBatchSize = 50
+
+const batchedIPAllowListEntrySelection = `{
+    ipAllowListEntry {
+      id
+      allowListValue
+      name
+      isActive
+      createdAt
+      updatedAt
+      owner {
+        __typename
+        ... on Organization {
+          login
+        }
+        ... on Enterprise {
+          slug
+        }
+        ... on App {
+          id
+        }
+      }
+    }
+  }`
+
+// IPAllowListChangeResult is a result of a single change applied with ApplyIPAllowListChanges.
+type IPAllowListChangeResult struct {
+	// Entry is the created or the updated entry, or the deleted entry with only its ID set. It is nil if Err is set.
+	Entry *IPAllowListEntry
+	Err   error
+}
+
+// IPAllowListChangesResult holds results of changes applied with ApplyIPAllowListChanges.
+type IPAllowListChangesResult struct {
+	// Creates holds results of creates in the order they were given.
+	Creates []IPAllowListChangeResult
+	// Updates holds results of updates keyed by entry IDs.
+	Updates map[string]IPAllowListChangeResult
+	// Deletes holds results of deletes keyed by entry IDs.
+	Deletes map[string]IPAllowListChangeResult
+}
+
+// batchedMutation is a single aliased mutation of a batch.
+type batchedMutation struct {
+	alias     string
+	field     string
+	variables map[string]string
+	values    Variables
+	handle    func(data json.RawMessage, err error)
+}
+
+type ownedIPAllowListEntry struct {
+	IPAllowListEntry
+	Owner *ipAllowListEntryOwner `json:"owner"`
+}
+
+type batchedIPAllowListEntryResponse struct {
+	IPAllowListEntry *ownedIPAllowListEntry `json:"ipAllowListEntry"`
+}
+
+// WithMutationBatchSize sets how many mutations ApplyIPAllowListChanges sends in a single request. Default: 50.
+// size must be >= 1, otherwise the option is ignored.
+func WithMutationBatchSize(size int) ClientOption {
+	return func(options *ClientOptions) {
+		if size >= 1 {
+			options.mutationBatchSize = size
+		}
+	}
+}
+
+// ApplyIPAllowListChanges deletes entries with given IDs, updates entries keyed by their IDs and creates entries for a given ownerID (organization, enterprise or app).
+// Changes are sent as aliased mutations batched into as few requests as WithMutationBatchSize allows, in the order of deletes, updates and creates.
+// A failure of a change does not stop other changes. Results and errors are reported per change, and the returned error combines all errors.
+// Cached entries are kept up to date like with CreateIPAllowListEntry, UpdateIPAllowListEntry and DeleteIPAllowListEntry.
+func (c *Client) ApplyIPAllowListChanges(ctx context.Context, ownerID string, creates []IPAllowListEntryParameters, updates map[string]IPAllowListEntryParameters, deletes []string) (*IPAllowListChangesResult, error) {
+	result := &IPAllowListChangesResult{
+		Creates: make([]IPAllowListChangeResult, len(creates)),
+		Updates: make(map[string]IPAllowListChangeResult, len(updates)),
+		Deletes: make(map[string]IPAllowListChangeResult, len(deletes)),
+	}
+
+	mutations := make([]batchedMutation, 0, len(deletes)+len(updates)+len(creates))
+	for i, entryID := range deletes {
+		mutations = append(mutations, c.batchedDelete(ctx, fmt.Sprintf("d%d", i), entryID, result))
+	}
+	updatedIDs := make([]string, 0, len(updates))
+	for entryID := range updates {
+		updatedIDs = append(updatedIDs, entryID)
+	}
+	sort.Strings(updatedIDs)
+	for i, entryID := range updatedIDs {
+		mutations = append(mutations, c.batchedUpdate(ctx, fmt.Sprintf("u%d", i), entryID, updates[entryID], result))
+	}
+	for i, params := range creates {
+		mutations = append(mutations, c.batchedCreate(ctx, fmt.Sprintf("c%d", i), ownerID, params, i, result))
+	}
+
+	for start := 0; start < len(mutations); start += c.mutationBatchSize {
+		end := start + c.mutationBatchSize
+		if end > len(mutations) {
+			end = len(mutations)
+		}
+		c.applyBatch(ctx, mutations[start:end])
+	}
+
+	var errs error
+	for _, entryID := range deletes {
+		errs = appendChangeError(errs, result.Deletes[entryID])
+	}
+	for _, entryID := range updatedIDs {
+		errs = appendChangeError(errs, result.Updates[entryID])
+	}
+	for _, r := range result.Creates {
+		errs = appendChangeError(errs, r)
+	}
+	if errs != nil {
+		return result, errors.Wrap(errs, "ApplyIPAllowListChanges error")
+	}
+	return result, nil
+}
+
+func appendChangeError(errs error, r IPAllowListChangeResult) error {
+	if r.Err == nil {
+		return errs
+	}
+	return multierror.Append(errs, r.Err)
+}
+
+func (c *Client) batchedDelete(ctx context.Context, alias string, entryID string, result *IPAllowListChangesResult) batchedMutation {
+	return batchedMutation{
+		alias:     alias,
+		field:     fmt.Sprintf("deleteIpAllowListEntry(input: {ipAllowListEntryId: $%[1]s_entryId}) {\n    ipAllowListEntry {\n      id\n    }\n  }", alias),
+		variables: map[string]string{"entryId": "ID!"},
+		values:    Variables{"entryId": entryID},
+		handle: func(data json.RawMessage, err error) {
+			if err != nil {
+				if errors.Is(err, ErrNotFound) {
+					c.cacheDeletedEntry(ctx, entryID)
+				}
+				result.Deletes[entryID] = IPAllowListChangeResult{Err: errors.Wrapf(err, "delete of entry %s error", entryID)}
+				return
+			}
+			c.cacheDeletedEntry(ctx, entryID)
+			result.Deletes[entryID] = IPAllowListChangeResult{Entry: &IPAllowListEntry{ID: entryID}}
+		},
+	}
+}
+
+func (c *Client) batchedUpdate(ctx context.Context, alias string, entryID string, params IPAllowListEntryParameters, result *IPAllowListChangesResult) batchedMutation {
+	return batchedMutation{
+		alias:     alias,
+		field:     fmt.Sprintf("updateIpAllowListEntry(\n    input: {ipAllowListEntryId: $%[1]s_entryId, allowListValue: $%[1]s_value, isActive: $%[1]s_isActive, name: $%[1]s_name}\n  ) %[2]s", alias, batchedIPAllowListEntrySelection),
+		variables: map[string]string{"entryId": "ID!", "name": "String!", "value": "String!", "isActive": "Boolean!"},
+		values:    Variables{"entryId": entryID, "name": params.Name, "value": params.Value, "isActive": params.IsActive},
+		handle: func(data json.RawMessage, err error) {
+			entry, err := batchedEntry(data, err)
+			if err != nil {
+				if errors.Is(err, ErrNotFound) {
+					c.cacheDeletedEntry(ctx, entryID)
+				}
+				result.Updates[entryID] = IPAllowListChangeResult{Err: errors.Wrapf(err, "update of entry %s error", entryID)}
+				return
+			}
+			c.cacheUpdatedEntry(ctx, entry.IPAllowListEntry)
+			result.Updates[entryID] = IPAllowListChangeResult{Entry: &entry.IPAllowListEntry}
+		},
+	}
+}
+
+func (c *Client) batchedCreate(ctx context.Context, alias string, ownerID string, params IPAllowListEntryParameters, i int, result *IPAllowListChangesResult) batchedMutation {
+	return batchedMutation{
+		alias:     alias,
+		field:     fmt.Sprintf("createIpAllowListEntry(\n    input: {ownerId: $%[1]s_ownerId, allowListValue: $%[1]s_value, isActive: $%[1]s_isActive, name: $%[1]s_name}\n  ) %[2]s", alias, batchedIPAllowListEntrySelection),
+		variables: map[string]string{"ownerId": "ID!", "name": "String", "value": "String!", "isActive": "Boolean!"},
+		values:    Variables{"ownerId": ownerID, "name": nullIfEmpty(params.Name), "value": params.Value, "isActive": params.IsActive},
+		handle: func(data json.RawMessage, err error) {
+			entry, err := batchedEntry(data, err)
+			if err != nil {
+				result.Creates[i] = IPAllowListChangeResult{Err: errors.Wrapf(err, "create of entry %s error", params.Value)}
+				return
+			}
+			c.cacheCreatedEntry(ctx, entry.Owner, entry.IPAllowListEntry)
+			result.Creates[i] = IPAllowListChangeResult{Entry: &entry.IPAllowListEntry}
+		},
+	}
+}
+
+// batchedEntry unmarshals an entry returned by an aliased mutation.
+func batchedEntry(data json.RawMessage, err error) (*ownedIPAllowListEntry, error) {
+	if err != nil {
+		return nil, err
+	}
+	res := new(batchedIPAllowListEntryResponse)
+	err = json.Unmarshal(data, res)
+	if err != nil {
+		return nil, errors.Wrap(err, "response unmarshalling error")
+	}
+	if res.IPAllowListEntry == nil {
+		return nil, errors.New("response without an entry")
+	}
+	return res.IPAllowListEntry, nil
+}
+
+// applyBatch sends mutations in a single request and hands each of them its data or errors.
+// Errors without a path are not attributable to a single mutation, so they are handed to all mutations without data.
+func (c *Client) applyBatch(ctx context.Context, mutations []batchedMutation) {
+	declarations := make([]string, 0, 4*len(mutations))
+	fields := make([]string, 0, len(mutations))
+	values := make(Variables, 4*len(mutations))
+	for _, m := range mutations {
+		names := make([]string, 0, len(m.variables))
+		for name := range m.variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			declarations = append(declarations, fmt.Sprintf("$%s_%s: %s", m.alias, name, m.variables[name]))
+			values[m.alias+"_"+name] = m.values[name]
+		}
+		fields = append(fields, fmt.Sprintf("  %s: %s", m.alias, m.field))
+	}
+	reqData := GraphQLRequest{
+		Query:     fmt.Sprintf("mutation ApplyIpAllowListChanges(%s) {\n%s\n}", strings.Join(declarations, ", "), strings.Join(fields, "\n")),
+		Variables: values,
+	}
+
+	gqlRes, err := c.doGraphQLRequest(ctx, reqData)
+	if err != nil {
+		for _, m := range mutations {
+			m.handle(nil, err)
+		}
+		return
+	}
+
+	data := make(map[string]json.RawMessage, len(mutations))
+	if len(gqlRes.Data) > 0 {
+		err = json.Unmarshal(gqlRes.Data, &data)
+		if err != nil {
+			err = errors.Wrap(err, "response unmarshalling error")
+			for _, m := range mutations {
+				m.handle(nil, err)
+			}
+			return
+		}
+	}
+
+	errsByAlias := make(map[string][]Error, len(gqlRes.Errors))
+	var unattributed []Error
+	for _, e := range gqlRes.Errors {
+		e.RequestID = gqlRes.requestID
+		alias, ok := errorAlias(e)
+		if !ok {
+			unattributed = append(unattributed, e)
+			continue
+		}
+		errsByAlias[alias] = append(errsByAlias[alias], e)
+	}
+
+	for _, m := range mutations {
+		errs := errsByAlias[m.alias]
+		d, ok := data[m.alias]
+		if len(errs) == 0 && (!ok || string(d) == "null") {
+			errs = unattributed
+		}
+		if len(errs) > 0 {
+			m.handle(nil, GraphQLErrors{Errors: errs})
+			continue
+		}
+		if !ok {
+			m.handle(nil, errors.New("response without a result"))
+			continue
+		}
+		m.handle(d, nil)
+	}
+}
+
+// errorAlias returns an alias of a mutation an error refers to with its path.
+func errorAlias(e Error) (string, bool) {
+	if len(e.Path) == 0 {
+		return "", false
+	}
+	alias, ok := e.Path[0].(string)
+	return alias, ok
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
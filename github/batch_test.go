@@ -0,0 +1,151 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyIPAllowListChanges(t *testing.T) {
+	// given
+	created := IPAllowListEntry{ID: "created-id", AllowListValue: "5.6.7.8/32", IsActive: true, Name: "created", CreatedAt: truncateToGitHubPrecision(time.Now()), UpdatedAt: truncateToGitHubPrecision(time.Now())}
+	updated := IPAllowListEntry{ID: "updated-id", AllowListValue: "1.2.3.4/32", IsActive: false, Name: "updated", CreatedAt: truncateToGitHubPrecision(time.Now()), UpdatedAt: truncateToGitHubPrecision(time.Now())}
+	var requests []GraphQLRequest
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"data": {"d0": {"ipAllowListEntry": {"id": "deleted-id"}}, "u0": %s, "c0": %s}}`, batchedEntryResponse(updated), batchedEntryResponse(created))))
+	}))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	result, err := client.ApplyIPAllowListChanges(context.TODO(), "some-owner-id",
+		[]IPAllowListEntryParameters{{Name: created.Name, Value: created.AllowListValue, IsActive: created.IsActive}},
+		map[string]IPAllowListEntryParameters{updated.ID: {Name: updated.Name, Value: updated.AllowListValue, IsActive: updated.IsActive}},
+		[]string{"deleted-id"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []IPAllowListChangeResult{{Entry: &created}}, result.Creates)
+	assert.Equal(t, map[string]IPAllowListChangeResult{updated.ID: {Entry: &updated}}, result.Updates)
+	assert.Equal(t, map[string]IPAllowListChangeResult{"deleted-id": {Entry: &IPAllowListEntry{ID: "deleted-id"}}}, result.Deletes)
+
+	assert.Len(t, requests, 1)
+	query := requests[0].Query
+	assert.Less(t, strings.Index(query, "d0: deleteIpAllowListEntry"), strings.Index(query, "u0: updateIpAllowListEntry"))
+	assert.Less(t, strings.Index(query, "u0: updateIpAllowListEntry"), strings.Index(query, "c0: createIpAllowListEntry"))
+	assert.Equal(t, "some-owner-id", requests[0].Variables["c0_ownerId"])
+	assert.Equal(t, "deleted-id", requests[0].Variables["d0_entryId"])
+	assert.Equal(t, "updated-id", requests[0].Variables["u0_entryId"])
+}
+
+func TestApplyIPAllowListChangesInBatches(t *testing.T) {
+	// given
+	var batchSizes []int
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		batchSizes = append(batchSizes, strings.Count(req.Query, "deleteIpAllowListEntry("))
+		data := make(map[string]any)
+		for name, value := range req.Variables {
+			data[strings.TrimSuffix(name, "_entryId")] = map[string]any{"ipAllowListEntry": map[string]any{"id": value}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithMutationBatchSize(2))
+
+	// when
+	result, err := client.ApplyIPAllowListChanges(context.TODO(), "some-owner-id", nil, nil, []string{"id-1", "id-2", "id-3", "id-4", "id-5"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 2, 1}, batchSizes)
+	assert.Len(t, result.Deletes, 5)
+	for id, r := range result.Deletes {
+		assert.NoError(t, r.Err)
+		assert.Equal(t, id, r.Entry.ID)
+	}
+}
+
+func TestApplyIPAllowListChangesReportsErrorsPerChange(t *testing.T) {
+	// given
+	created := IPAllowListEntry{ID: "created-id", AllowListValue: "5.6.7.8/32", IsActive: true, Name: "created", CreatedAt: truncateToGitHubPrecision(time.Now()), UpdatedAt: truncateToGitHubPrecision(time.Now())}
+	gitHubGraphQLAPIMock := serverReturning(fmt.Sprintf(`{
+    "data": {"d0": null, "c0": %s, "c1": null},
+    "errors": [
+        {"type": "NOT_FOUND", "path": ["d0"], "message": "Could not resolve to a node with the global id of 'missing-id'."},
+        {"type": "UNPROCESSABLE", "path": ["c1"], "message": "Allow list value is invalid"}
+    ]
+}`, batchedEntryResponse(created)))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	result, err := client.ApplyIPAllowListChanges(context.TODO(), "some-owner-id",
+		[]IPAllowListEntryParameters{{Name: created.Name, Value: created.AllowListValue, IsActive: created.IsActive}, {Value: "invalid"}},
+		nil,
+		[]string{"missing-id"})
+
+	// then
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.ErrorIs(t, result.Deletes["missing-id"].Err, ErrNotFound)
+	assert.Nil(t, result.Deletes["missing-id"].Entry)
+	assert.Equal(t, &created, result.Creates[0].Entry)
+	assert.NoError(t, result.Creates[0].Err)
+	assert.ErrorIs(t, result.Creates[1].Err, ErrValidation)
+	assert.ErrorContains(t, result.Creates[1].Err, "create of entry invalid error")
+}
+
+func TestApplyIPAllowListChangesWithFailingServer(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := serverReturningAnEmptyResponseWith(http.StatusInternalServerError)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	result, err := client.ApplyIPAllowListChanges(context.TODO(), "some-owner-id", []IPAllowListEntryParameters{{Value: "1.2.3.4"}}, nil, []string{"some-id"})
+
+	// then
+	var target ErrorWithStatusCode
+	assert.ErrorAs(t, err, &target)
+	assert.Equal(t, http.StatusInternalServerError, target.StatusCode)
+	assert.Error(t, result.Creates[0].Err)
+	assert.Error(t, result.Deletes["some-id"].Err)
+}
+
+func TestApplyIPAllowListChangesKeepsCachedEntriesUpToDate(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, _ := serverReturningConsecutiveResponses(
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		fmt.Sprintf(`{"data": {"d0": {"ipAllowListEntry": {"id": "%s"}}, "c0": %s}}`, someCachedEntry.ID, batchedEntryResponseWithOwner(someCreatedEntry, `{"login": "some organization"}`)),
+	)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	_, _ = client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// when
+	_, err := client.ApplyIPAllowListChanges(context.TODO(), "some-owner-id",
+		[]IPAllowListEntryParameters{{Name: someCreatedEntry.Name, Value: someCreatedEntry.AllowListValue, IsActive: someCreatedEntry.IsActive}},
+		nil,
+		[]string{someCachedEntry.ID})
+	entries, _ := client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCreatedEntry}, entries)
+}
+
+func batchedEntryResponse(entry IPAllowListEntry) string {
+	return batchedEntryResponseWithOwner(entry, "null")
+}
+
+func batchedEntryResponseWithOwner(entry IPAllowListEntry, owner string) string {
+	return fmt.Sprintf(`{"ipAllowListEntry": {"id": "%s", "createdAt": "%s", "updatedAt": "%s", "allowListValue": "%s", "isActive": %t, "name": "%s", "owner": %s}}`,
+		entry.ID, entry.CreatedAt.Format(gitHubTimeFormat), entry.UpdatedAt.Format(gitHubTimeFormat), entry.AllowListValue, entry.IsActive, entry.Name, owner)
+}
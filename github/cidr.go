@@ -0,0 +1,114 @@
+package github
+
+import (
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Prefix parses the CIDR into a range of IPv4 or IPv6 addresses. A single IP address is treated as a range holding only that address.
+// Returns an error for malformed values, IPv6 zones, prefix lengths exceeding the address family's bit length
+// and ranges with host bits set, e.g. 1.2.3.4/24.
+func (c CIDR) Prefix() (netip.Prefix, error) {
+	value := string(c)
+	rawAddr, rawBits, hasBits := strings.Cut(value, "/")
+
+	addr, err := netip.ParseAddr(rawAddr)
+	if err != nil {
+		return netip.Prefix{}, errors.Errorf("%q is neither an IP address nor a CIDR", value)
+	}
+	if addr.Zone() != "" {
+		return netip.Prefix{}, errors.Errorf("%q has an IPv6 zone which is not supported", value)
+	}
+	if !hasBits {
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	bits, err := strconv.Atoi(rawBits)
+	if err != nil || bits < 0 || strings.HasPrefix(rawBits, "+") {
+		return netip.Prefix{}, errors.Errorf("%q has an invalid prefix length", value)
+	}
+	if bits > addr.BitLen() {
+		return netip.Prefix{}, errors.Errorf("%q has a prefix length exceeding %d bits of an IPv%d address", value, addr.BitLen(), ipVersion(addr))
+	}
+
+	prefix := netip.PrefixFrom(addr, bits)
+	if prefix.Masked() != prefix {
+		return netip.Prefix{}, errors.Errorf("%q has host bits set, did you mean %q?", value, prefix.Masked().String())
+	}
+	return prefix, nil
+}
+
+// IPVersion returns 4 for IPv4 and 6 for IPv6 CIDRs.
+func (c CIDR) IPVersion() (int, error) {
+	prefix, err := c.Prefix()
+	if err != nil {
+		return 0, err
+	}
+	return ipVersion(prefix.Addr()), nil
+}
+
+// Validate returns an error if the CIDR is neither a single IP address nor a range of IP addresses accepted by GitHub.
+func (c CIDR) Validate() error {
+	_, err := c.Prefix()
+	return err
+}
+
+// Normalize returns the CIDR in canonical CIDR notation with an explicit prefix length, e.g. 1.2.3.4/32 for 1.2.3.4.
+func (c CIDR) Normalize() (CIDR, error) {
+	prefix, err := c.Prefix()
+	if err != nil {
+		return c, err
+	}
+	return CIDR(prefix.String()), nil
+}
+
+// NormalizedCIDR returns a value in CIDR notation with an explicit prefix length, or the value itself if it is invalid.
+// It is used to match entries regardless of their notation, e.g. as a map key.
+func NormalizedCIDR(value CIDR) CIDR {
+	normalized, err := value.Normalize()
+	if err != nil {
+		return value
+	}
+	return normalized
+}
+
+// Equal reports whether both CIDRs describe the same range of IP addresses regardless of their notation,
+// e.g. 1.2.3.4 and 1.2.3.4/32 or 2001:DB8::/32 and 2001:0db8::/32.
+// Invalid CIDRs are equal only if they are exactly the same.
+func (c CIDR) Equal(other CIDR) bool {
+	if c == other {
+		return true
+	}
+	prefix, err := c.Prefix()
+	if err != nil {
+		return false
+	}
+	otherPrefix, err := other.Prefix()
+	if err != nil {
+		return false
+	}
+	return prefix == otherPrefix
+}
+
+// Contains reports whether the CIDR fully contains the other CIDR. Returns false if either is invalid.
+func (c CIDR) Contains(other CIDR) bool {
+	prefix, err := c.Prefix()
+	if err != nil {
+		return false
+	}
+	otherPrefix, err := other.Prefix()
+	if err != nil {
+		return false
+	}
+	return prefix.Bits() <= otherPrefix.Bits() && prefix.Contains(otherPrefix.Addr())
+}
+
+func ipVersion(addr netip.Addr) int {
+	if addr.Is4() {
+		return 4
+	}
+	return 6
+}
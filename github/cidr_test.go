@@ -0,0 +1,149 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCIDRValidate(t *testing.T) {
+	tests := []struct {
+		cidr          CIDR
+		expectedError string
+	}{
+		{cidr: "1.2.3.4"},
+		{cidr: "1.2.3.4/32"},
+		{cidr: "10.0.0.0/8"},
+		{cidr: "0.0.0.0/0"},
+		{cidr: "2001:db8::/32"},
+		{cidr: "2001:DB8::/32"},
+		{cidr: "2001:db8::1"},
+		{cidr: "2001:db8::1/128"},
+		{cidr: "::/0"},
+		{cidr: "10.0.0.0/33", expectedError: "exceeding 32 bits of an IPv4 address"},
+		{cidr: "2001:db8::/129", expectedError: "exceeding 128 bits of an IPv6 address"},
+		{cidr: "10.0.0.0/-1", expectedError: "invalid prefix length"},
+		{cidr: "10.0.0.0/", expectedError: "invalid prefix length"},
+		{cidr: "2001:db8::1/32", expectedError: `did you mean "2001:db8::/32"`},
+		{cidr: "fe80::1%eth0", expectedError: "IPv6 zone"},
+		{cidr: "1.2.3.4/24", expectedError: `did you mean "1.2.3.0/24"`},
+		{cidr: "1.2.3", expectedError: "neither an IP address nor a CIDR"},
+		{cidr: "", expectedError: "neither an IP address nor a CIDR"},
+		{cidr: "some value", expectedError: "neither an IP address nor a CIDR"},
+	}
+	for _, test := range tests {
+		t.Run(string(test.cidr), func(t *testing.T) {
+			// when
+			err := test.cidr.Validate()
+
+			// then
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestCIDRNormalize(t *testing.T) {
+	tests := []struct {
+		cidr     CIDR
+		expected CIDR
+	}{
+		{cidr: "1.2.3.4", expected: "1.2.3.4/32"},
+		{cidr: "1.2.3.4/32", expected: "1.2.3.4/32"},
+		{cidr: "10.0.0.0/8", expected: "10.0.0.0/8"},
+		{cidr: "2001:DB8::/32", expected: "2001:db8::/32"},
+		{cidr: "2001:0db8:0000::/32", expected: "2001:db8::/32"},
+		{cidr: "2001:db8::1", expected: "2001:db8::1/128"},
+	}
+	for _, test := range tests {
+		t.Run(string(test.cidr), func(t *testing.T) {
+			// when
+			normalized, err := test.cidr.Normalize()
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, normalized)
+		})
+	}
+}
+
+func TestNormalizedCIDR(t *testing.T) {
+	assert.Equal(t, CIDR("1.2.3.4/32"), NormalizedCIDR("1.2.3.4"))
+	assert.Equal(t, CIDR("2001:db8::/32"), NormalizedCIDR("2001:DB8::/32"))
+	assert.Equal(t, CIDR("1.2.3.4/24"), NormalizedCIDR("1.2.3.4/24"))
+	assert.Equal(t, CIDR(""), NormalizedCIDR(""))
+}
+
+func TestCIDRIPVersion(t *testing.T) {
+	tests := []struct {
+		cidr     CIDR
+		expected int
+	}{
+		{cidr: "1.2.3.4", expected: 4},
+		{cidr: "10.0.0.0/8", expected: 4},
+		{cidr: "2001:db8::/32", expected: 6},
+		{cidr: "::ffff:1.2.3.4", expected: 6},
+	}
+	for _, test := range tests {
+		t.Run(string(test.cidr), func(t *testing.T) {
+			// when
+			version, err := test.cidr.IPVersion()
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, version)
+		})
+	}
+}
+
+func TestCIDREqual(t *testing.T) {
+	tests := []struct {
+		cidr     CIDR
+		other    CIDR
+		expected bool
+	}{
+		{cidr: "1.2.3.4", other: "1.2.3.4/32", expected: true},
+		{cidr: "1.2.3.4/32", other: "1.2.3.4", expected: true},
+		{cidr: "10.0.0.0/8", other: "10.0.0.0/8", expected: true},
+		{cidr: "10.0.0.0/8", other: "10.0.0.0/16", expected: false},
+		{cidr: "1.2.3.4", other: "1.2.3.5", expected: false},
+		{cidr: "2001:DB8::/32", other: "2001:db8::/32", expected: true},
+		{cidr: "2001:0db8:0000::/32", other: "2001:db8::/32", expected: true},
+		{cidr: "2001:db8::1", other: "2001:db8::1/128", expected: true},
+		{cidr: "::ffff:1.2.3.4", other: "1.2.3.4", expected: false},
+		{cidr: "some value", other: "some value", expected: true},
+		{cidr: "some value", other: "1.2.3.4", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(string(test.cidr)+"="+string(test.other), func(t *testing.T) {
+			assert.Equal(t, test.expected, test.cidr.Equal(test.other))
+		})
+	}
+}
+
+func TestCIDRContains(t *testing.T) {
+	tests := []struct {
+		cidr     CIDR
+		other    CIDR
+		expected bool
+	}{
+		{cidr: "10.1.0.0/16", other: "10.1.2.3", expected: true},
+		{cidr: "10.1.0.0/16", other: "10.1.2.0/24", expected: true},
+		{cidr: "10.1.0.0/16", other: "10.1.0.0/16", expected: true},
+		{cidr: "10.1.0.0/16", other: "10.0.0.0/8", expected: false},
+		{cidr: "10.1.0.0/16", other: "10.2.0.1", expected: false},
+		{cidr: "1.2.3.4", other: "1.2.3.4/32", expected: true},
+		{cidr: "2001:db8::/32", other: "2001:DB8:1::/48", expected: true},
+		{cidr: "2001:db8::/32", other: "2001:db9::1", expected: false},
+		{cidr: "10.1.0.0/16", other: "::ffff:10.1.0.1", expected: false},
+		{cidr: "10.1.0.0/16", other: "some value", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(string(test.cidr)+">"+string(test.other), func(t *testing.T) {
+			assert.Equal(t, test.expected, test.cidr.Contains(test.other))
+		})
+	}
+}
@@ -5,27 +5,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 	"golang.org/x/sync/semaphore"
 	"io"
 	"net/http"
 	"sync"
+	"time"
 )
 
 const (
 	defaultAPIURL = "https://api.github.com/graphql"
+	// maxPageSize is the maximum number of nodes GitHub's GraphQL API returns per page.
+	maxPageSize = 100
 )
 
-type ErrorWithStatusCode struct {
-	StatusCode int
-	message    string
-}
-
-func (e ErrorWithStatusCode) Error() string {
-	return e.message
-}
+// ErrStopIteration is returned by a callback passed to an iterating function like ForEachOrganizationIPAllowListEntry to stop iterating without an error.
+var ErrStopIteration = errors.New("stop iteration")
 
 type Variables map[string]any
 
@@ -39,18 +35,11 @@ type PageInfo struct {
 	EndCursor   string `json:"endCursor"`
 }
 
-type Error struct {
-	Message   string `json:"message"`
-	Locations []struct {
-		Line   int `json:"line"`
-		Column int `json:"column"`
-	} `json:"locations"`
-	Path []string `json:"path"`
-}
-
 type GraphQLResponse struct {
 	Data   json.RawMessage `json:"data"`
 	Errors []Error         `json:"errors"`
+
+	requestID string
 }
 
 type Client struct {
@@ -58,19 +47,30 @@ type Client struct {
 	concurrencySemaphore *semaphore.Weighted
 	url                  string
 	headers              map[string]string
+	retryPolicy          RetryPolicy
+	rateLimiter          *rateLimiter
+	mutationBatchSize    int
+	pageSize             int
 
-	cacheEntries                  bool
-	organizationEntriesCache      map[string][]*IPAllowListEntry
-	organizationEntriesCacheMutex *sync.Mutex
-	enterpriseEntriesCache        map[string][]*IPAllowListEntry
-	enterpriseEntriesCacheMutex   *sync.Mutex
+	cacheEntries  bool
+	entriesCaches map[OwnerKind]*entriesCache
+
+	ownerIDCache      map[Owner]string
+	ownerIDCacheMutex *sync.Mutex
 }
 
 type ClientOptions struct {
-	concurrency   int64
-	graphQLAPIURL string
-	headers       map[string]string
-	cacheEntries  bool
+	concurrency     int64
+	graphQLAPIURL   string
+	headers         map[string]string
+	cacheEntries    bool
+	entriesCacheTTL time.Duration
+	entriesCacheDir string
+	retryPolicy     RetryPolicy
+
+	rateLimitThreshold int
+	mutationBatchSize  int
+	pageSize           int
 }
 
 type ClientOption func(options *ClientOptions)
@@ -78,7 +78,14 @@ type ClientOption func(options *ClientOptions)
 // NewAuthenticatedGitHubClient creates a new authenticated client (using Personal Access Token (classic)) with given ClientOptions
 func NewAuthenticatedGitHubClient(ctx context.Context, token string, opts ...ClientOption) *Client {
 	authToken := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	oauthClient := oauth2.NewClient(ctx, authToken)
+
+	return NewGitHubClientWithTokenSource(ctx, authToken, opts...)
+}
+
+// NewGitHubClientWithTokenSource creates a new client authenticated with tokens of a given oauth2.TokenSource with given ClientOptions.
+// A token is reused until it expires, then a new one is requested from tokenSource. It allows plugging in rotating credentials.
+func NewGitHubClientWithTokenSource(ctx context.Context, tokenSource oauth2.TokenSource, opts ...ClientOption) *Client {
+	oauthClient := oauth2.NewClient(ctx, oauth2.ReuseTokenSource(nil, tokenSource))
 
 	return NewGitHubClient(oauthClient, opts...)
 }
@@ -86,32 +93,49 @@ func NewAuthenticatedGitHubClient(ctx context.Context, token string, opts ...Cli
 // NewGitHubClient creates a new client with a given http.Client and ClientOptions.
 // This client does not handle authorization nor authentication, instead relies on http.Client implementation.
 func NewGitHubClient(httpClient *http.Client, opts ...ClientOption) *Client {
-	options := &ClientOptions{
-		concurrency:   int64(1),
-		graphQLAPIURL: defaultAPIURL,
-		cacheEntries:  true,
-	}
-	for _, opt := range opts {
-		opt(options)
-	}
+	options := newClientOptions(opts...)
 
 	c := &Client{
 		http:                 httpClient,
 		concurrencySemaphore: semaphore.NewWeighted(options.concurrency),
 		url:                  options.graphQLAPIURL,
 		headers:              options.headers,
+		retryPolicy:          options.retryPolicy,
+		rateLimiter:          newRateLimiter(options.rateLimitThreshold),
+		mutationBatchSize:    options.mutationBatchSize,
+		pageSize:             options.pageSize,
+		ownerIDCache:         make(map[Owner]string, 8),
+		ownerIDCacheMutex:    &sync.Mutex{},
 	}
 
 	if options.cacheEntries {
 		c.cacheEntries = true
-		c.organizationEntriesCache = make(map[string][]*IPAllowListEntry, 8)
-		c.organizationEntriesCacheMutex = &sync.Mutex{}
-		c.enterpriseEntriesCache = make(map[string][]*IPAllowListEntry, 8)
-		c.enterpriseEntriesCacheMutex = &sync.Mutex{}
+		c.entriesCaches = make(map[OwnerKind]*entriesCache, len(ownerKinds))
+		for _, kind := range ownerKinds {
+			var diskCache *entriesDiskCache
+			if options.entriesCacheDir != "" && options.entriesCacheTTL > 0 {
+				diskCache = newEntriesDiskCache(options.entriesCacheDir, options.graphQLAPIURL, string(kind))
+			}
+			c.entriesCaches[kind] = newEntriesCache(options.entriesCacheTTL, diskCache)
+		}
 	}
 	return c
 }
 
+func newClientOptions(opts ...ClientOption) *ClientOptions {
+	options := &ClientOptions{
+		concurrency:       int64(1),
+		graphQLAPIURL:     defaultAPIURL,
+		cacheEntries:      true,
+		mutationBatchSize: defaultMutationBatchSize,
+		pageSize:          maxPageSize,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
 // WithConcurrency determines maximum number of concurrent requests to the GitHub GraphQL API. Used to control rate limiting.
 // concurrency must be >= 1, otherwise the option is ignored.
 func WithConcurrency(concurrency int64) ClientOption {
@@ -136,6 +160,16 @@ func WithHeaders(headers map[string]string) ClientOption {
 	}
 }
 
+// WithPageSize sets how many entries are requested per page when entries are listed. Default: 100.
+// size must be between 1 and 100, the maximum GitHub allows, otherwise the option is ignored.
+func WithPageSize(size int) ClientOption {
+	return func(options *ClientOptions) {
+		if size >= 1 && size <= maxPageSize {
+			options.pageSize = size
+		}
+	}
+}
+
 // WithEntriesCaching enables an entries cache. It reduces number of calls to GitHub's GraphQL API reducing rate limiting pressure.
 func WithEntriesCaching() ClientOption {
 	return func(options *ClientOptions) {
@@ -152,59 +186,99 @@ func WithoutEntriesCaching() ClientOption {
 	}
 }
 
+// WithEntriesCacheTTL sets how long cached entries are used before they are fetched again. By default, cached entries never expire.
+// ttl must be >= 0, otherwise the option is ignored.
+func WithEntriesCacheTTL(ttl time.Duration) ClientOption {
+	return func(options *ClientOptions) {
+		if ttl >= 0 {
+			options.entriesCacheTTL = ttl
+		}
+	}
+}
+
+// WithEntriesDiskCache additionally stores cached entries as files in dir, so that they are reused by other clients and processes,
+// e.g. by `terraform apply` following `terraform plan`. Files are keyed by GitHub's GraphQL API URL and an owner, and are locked while entries are fetched.
+// Entries cached on disk are only used within a TTL set with WithEntriesCacheTTL, so the option has no effect without it.
+func WithEntriesDiskCache(dir string) ClientOption {
+	return func(options *ClientOptions) {
+		options.entriesCacheDir = dir
+	}
+}
+
 func paginate[T any, L any](ctx context.Context, c *Client, reqData GraphQLRequest, pageExtractor func(*T) []*L, pageInfoExtractor func(*T) PageInfo) ([]*L, error) {
 	entries := make([]*L, 0, 10)
-	hasNextPage := true
-	endCursor := ""
-	for hasNextPage {
-		if endCursor != "" {
-			reqData.Variables["after"] = endCursor
-		}
+	err := forEachPage[T, L](ctx, c, reqData, pageExtractor, pageInfoExtractor, func(page []*L) error {
+		entries = append(entries, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
 
+// forEachPage requests pages of c.pageSize nodes one after another and calls fn with nodes of each page, so that only one page is held in memory.
+// Iteration stops at the first error returned by fn, which is returned unless it is ErrStopIteration.
+func forEachPage[T any, L any](ctx context.Context, c *Client, reqData GraphQLRequest, pageExtractor func(*T) []*L, pageInfoExtractor func(*T) PageInfo, fn func([]*L) error) error {
+	reqData.Variables["first"] = c.pageSize
+	for {
 		resData, err := doRequest[T](ctx, c, reqData)
 		if err != nil {
-			return entries, errors.Wrap(err, "pagination error")
+			return errors.Wrap(err, "pagination error")
 		}
 
-		entries = append(entries, pageExtractor(resData)...)
+		err = fn(pageExtractor(resData))
+		if errors.Is(err, ErrStopIteration) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 
 		pageInfo := pageInfoExtractor(resData)
-		if pageInfo.HasNextPage {
-			endCursor = pageInfo.EndCursor
-		} else {
-			hasNextPage = false
+		if !pageInfo.HasNextPage {
+			return nil
 		}
+		reqData.Variables["after"] = pageInfo.EndCursor
 	}
-
-	return entries, nil
 }
 
 func doRequest[T any](ctx context.Context, c *Client, reqData GraphQLRequest) (*T, error) {
-	req, err := c.createRequestWithBody(ctx, reqData)
+	gqlRes, err := c.doGraphQLRequest(ctx, reqData)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := c.doRequestWithConcurrency(ctx, req)
+	err = handleErrors(gqlRes)
 	if err != nil {
 		return nil, err
 	}
 
-	gqlRes, err := handleGraphQLResponse(res)
+	resData, err := toResponseData[T](gqlRes)
+	if err != nil {
+		return nil, err
+	}
+	return resData, nil
+}
+
+// doGraphQLRequest sends a request and returns GitHub's GraphQL API response, which may hold errors.
+func (c *Client) doGraphQLRequest(ctx context.Context, reqData GraphQLRequest) (*GraphQLResponse, error) {
+	req, err := c.createRequestWithBody(ctx, reqData)
 	if err != nil {
 		return nil, err
 	}
 
-	err = handleErrors(gqlRes)
+	res, err := c.doRequestWithConcurrency(ctx, req, !isMutation(reqData.Query))
 	if err != nil {
 		return nil, err
 	}
 
-	resData, err := toResponseData[T](gqlRes)
+	gqlRes, err := handleGraphQLResponse(res)
 	if err != nil {
 		return nil, err
 	}
-	return resData, nil
+	c.rateLimiter.updateFromGraphQLResponse(gqlRes)
+	return gqlRes, nil
 }
 
 func (c *Client) createRequestWithBody(ctx context.Context, reqData GraphQLRequest) (*http.Request, error) {
@@ -224,8 +298,50 @@ func (c *Client) createRequestWithBody(ctx context.Context, reqData GraphQLReque
 	return req, nil
 }
 
-func (c *Client) doRequestWithConcurrency(ctx context.Context, req *http.Request) (*http.Response, error) {
-	err := c.concurrencySemaphore.Acquire(ctx, int64(1))
+// doRequestWithConcurrency sends a request, retrying it according to the client's RetryPolicy.
+// A request which is not idempotent is only retried if it was never sent. The concurrency semaphore is released while waiting for a retry.
+func (c *Client) doRequestWithConcurrency(ctx context.Context, req *http.Request, idempotent bool) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := c.doSingleRequestWithConcurrency(ctx, req)
+		wait, retry := c.retryPolicy.retryWait(ctx, attempt, idempotent, res, err, time.Now())
+		if !retry {
+			return res, err
+		}
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		err = sleep(ctx, wait)
+		if err != nil {
+			return nil, errors.Wrap(err, "retry wait error")
+		}
+		req, err = rewindRequest(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// rewindRequest clones a request with a fresh body, so that it can be sent again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	retried := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, errors.Wrap(err, "request body rewind error")
+		}
+		retried.Body = body
+	}
+	return retried, nil
+}
+
+func (c *Client) doSingleRequestWithConcurrency(ctx context.Context, req *http.Request) (*http.Response, error) {
+	err := c.rateLimiter.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = c.concurrencySemaphore.Acquire(ctx, int64(1))
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot acquire semaphore")
 	}
@@ -234,6 +350,7 @@ func (c *Client) doRequestWithConcurrency(ctx context.Context, req *http.Request
 	if err != nil {
 		return nil, errors.Wrap(err, "http request call error")
 	}
+	c.rateLimiter.updateFromHeaders(res.Header)
 	return res, nil
 }
 
@@ -242,40 +359,34 @@ func handleGraphQLResponse(res *http.Response) (*GraphQLResponse, error) {
 
 	resBytes, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, ErrorWithStatusCode{
-			StatusCode: res.StatusCode,
-			message:    fmt.Sprintf("response body read error: %s", err.Error()),
-		}
+		return nil, newErrorWithStatusCode(res, nil, fmt.Sprintf("response body read error: %s", err.Error()))
 	}
 
 	if res.StatusCode >= 300 {
-		return nil, ErrorWithStatusCode{
-			StatusCode: res.StatusCode,
-			message:    fmt.Sprintf("GitHub API response: %s", string(resBytes)),
-		}
+		return nil, newErrorWithStatusCode(res, resBytes, fmt.Sprintf("GitHub API response: %s", string(resBytes)))
 	}
 
 	gqlRes := new(GraphQLResponse)
 	err = json.Unmarshal(resBytes, gqlRes)
 	if err != nil {
-		return nil, ErrorWithStatusCode{
-			StatusCode: res.StatusCode,
-			message:    fmt.Sprintf("response unmarshalling error: %s", err.Error()),
-		}
+		return nil, newErrorWithStatusCode(res, resBytes, fmt.Sprintf("response unmarshalling error: %s", err.Error()))
 	}
+	gqlRes.requestID = res.Header.Get("X-GitHub-Request-Id")
 
 	return gqlRes, err
 }
 
+// handleErrors returns GraphQLErrors if the response has any errors.
 func handleErrors(gqlRes *GraphQLResponse) error {
-	if len(gqlRes.Errors) > 0 {
-		var errs error
-		for _, e := range gqlRes.Errors {
-			errs = multierror.Append(errs, errors.New(e.Message))
-		}
-		return errs
+	if len(gqlRes.Errors) == 0 {
+		return nil
+	}
+	errs := make([]Error, 0, len(gqlRes.Errors))
+	for _, e := range gqlRes.Errors {
+		e.RequestID = gqlRes.requestID
+		errs = append(errs, e)
 	}
-	return nil
+	return GraphQLErrors{Errors: errs}
 }
 
 func toResponseData[T any](gqlRes *GraphQLResponse) (*T, error) {
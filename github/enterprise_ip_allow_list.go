@@ -2,116 +2,46 @@ package github
 
 import (
 	"context"
-
-	"github.com/pkg/errors"
 )
 
-const getEnterpriseIDQuery = `
-query GetEnterpriseId($enterpriseName: String!) {
-  enterprise(slug: $enterpriseName) {
-    id
-  }
-}`
-
-type GetEnterpriseIDQueryResponse struct {
-	Enterprise struct {
-		ID string `json:"id"`
-	} `json:"enterprise"`
-}
-
-const getEnterpriseIPAllowListEntriesQuery = `
-query GetEnterpriseId($enterpriseName: String!, $after: String) {
-  enterprise(slug: $enterpriseName) {
-    ownerInfo {
-      ipAllowListEntries(first: 100, after: $after) {
-        nodes {
-          id
-          allowListValue
-          name
-          isActive
-          createdAt
-          updatedAt
-        }
-        pageInfo {
-          endCursor
-          hasNextPage
-          startCursor
-        }
-      }
-    }
-  }
-}`
-
-type GetEnterpriseIPAllowListQueryResponse struct {
-	Enterprise struct {
-		OwnerInfo struct {
-			IPAllowListEntries struct {
-				Nodes    []*IPAllowListEntry `json:"nodes"`
-				PageInfo PageInfo            `json:"pageInfo"`
-			} `json:"ipAllowListEntries"`
-		} `json:"ownerInfo"`
-	} `json:"enterprise"`
-}
-
 // GetEnterpriseIPAllowListEntries retrieves IP allow list entries for a given enterpriseName.
 func (c *Client) GetEnterpriseIPAllowListEntries(ctx context.Context, enterpriseName string) ([]*IPAllowListEntry, error) {
-	var entries []*IPAllowListEntry
-	var err error
-	if c.cacheEntries {
-		entries, err = c.getEnterpriseIPAllowListEntriesWithCache(ctx, enterpriseName)
-	} else {
-		entries, err = c.getEnterpriseIPAllowListEntries(ctx, enterpriseName)
-	}
-
-	return entries, err
+	return c.GetOwnerIPAllowListEntries(ctx, EnterpriseOwner(enterpriseName))
 }
 
-func (c *Client) getEnterpriseIPAllowListEntriesWithCache(ctx context.Context, enterpriseName string) ([]*IPAllowListEntry, error) {
-	c.enterpriseEntriesCacheMutex.Lock()
-	defer c.enterpriseEntriesCacheMutex.Unlock()
-
-	var entries []*IPAllowListEntry
-	var ok bool
-	entries, ok = c.enterpriseEntriesCache[enterpriseName]
-	if !ok {
-		var err error
-		entries, err = c.getEnterpriseIPAllowListEntries(ctx, enterpriseName)
-		if err != nil {
-			return entries, errors.Wrap(err, "getEnterpriseIPAllowListEntriesWithCache error")
-		}
-
-		c.enterpriseEntriesCache[enterpriseName] = entries
-	}
-	return entries, nil
+// GetEnterpriseIPAllowListEntryByID returns an entry with a given entryID among IP allow list entries of a given enterpriseName.
+// Entries are listed like with GetEnterpriseIPAllowListEntries, and looked up without scanning them when they are cached.
+// Returns an error matching ErrNotFound if there is no such entry, and a ListingError if entries cannot be listed.
+func (c *Client) GetEnterpriseIPAllowListEntryByID(ctx context.Context, enterpriseName string, entryID string) (*IPAllowListEntry, error) {
+	return c.GetOwnerIPAllowListEntryByID(ctx, EnterpriseOwner(enterpriseName), entryID)
 }
 
-func (c *Client) getEnterpriseIPAllowListEntries(ctx context.Context, enterpriseName string) ([]*IPAllowListEntry, error) {
-	reqData := GraphQLRequest{Query: getEnterpriseIPAllowListEntriesQuery, Variables: map[string]any{"enterpriseName": enterpriseName}}
-	entries, err := paginate[GetEnterpriseIPAllowListQueryResponse, IPAllowListEntry](ctx, c, reqData,
-		func(t *GetEnterpriseIPAllowListQueryResponse) []*IPAllowListEntry {
-			return t.Enterprise.OwnerInfo.IPAllowListEntries.Nodes
-		}, func(t *GetEnterpriseIPAllowListQueryResponse) PageInfo {
-			return t.Enterprise.OwnerInfo.IPAllowListEntries.PageInfo
-		})
+// GetEnterpriseIPAllowListEntriesByCIDR returns IP allow list entries of a given enterpriseName with a value equal to a given value regardless of its notation,
+// e.g. 1.2.3.4 and 1.2.3.4/32. Entries are listed like with GetEnterpriseIPAllowListEntries, and looked up without scanning them when they are cached.
+func (c *Client) GetEnterpriseIPAllowListEntriesByCIDR(ctx context.Context, enterpriseName string, value CIDR) ([]*IPAllowListEntry, error) {
+	return c.GetOwnerIPAllowListEntriesByCIDR(ctx, EnterpriseOwner(enterpriseName), value)
+}
 
-	if err != nil {
-		return []*IPAllowListEntry{}, errors.Wrap(err, "getEnterpriseIPAllowListEntries error")
-	}
-	return entries, nil
+// ForEachEnterpriseIPAllowListEntry calls fn with each IP allow list entry of a given enterpriseName, in the order GitHub lists them.
+// Entries are requested page by page as fn consumes them, so memory stays flat regardless of the number of entries, unless fresh entries are cached,
+// in which case fn is called with cached entries. Streamed entries are not cached.
+// Iteration stops at the first error returned by fn, which is returned unless it is ErrStopIteration.
+func (c *Client) ForEachEnterpriseIPAllowListEntry(ctx context.Context, enterpriseName string, fn func(*IPAllowListEntry) error) error {
+	return c.ForEachOwnerIPAllowListEntry(ctx, EnterpriseOwner(enterpriseName), fn)
 }
 
 // GetEnterpriseID fetches GitHub GraphQL API node_id for given enterpriseName.
+// IDs are cached per enterpriseName for the lifetime of the client as they never change.
 func (c *Client) GetEnterpriseID(ctx context.Context, enterpriseName string) (string, error) {
-	reqData := GraphQLRequest{
-		Query: getEnterpriseIDQuery,
-		Variables: map[string]any{
-			"enterpriseName": enterpriseName,
-		}}
+	return c.GetOwnerID(ctx, EnterpriseOwner(enterpriseName))
+}
 
-	resData, err := doRequest[GetEnterpriseIDQueryResponse](ctx, c, reqData)
-	if err != nil {
-		return "", errors.Wrap(err, "GetEnterpriseID error")
-	}
+// GetEnterpriseIPAllowListEnabledSetting fetches whether IP allow list is enabled for a given enterpriseName.
+func (c *Client) GetEnterpriseIPAllowListEnabledSetting(ctx context.Context, enterpriseName string) (IPAllowListEnabledSettingValue, error) {
+	return c.GetOwnerIPAllowListEnabledSetting(ctx, EnterpriseOwner(enterpriseName))
+}
 
-	return resData.Enterprise.ID, nil
+// GetEnterpriseIPAllowListForInstalledAppsEnabledSetting fetches whether IP allow list configuration of installed GitHub Apps is inherited by a given enterpriseName.
+func (c *Client) GetEnterpriseIPAllowListForInstalledAppsEnabledSetting(ctx context.Context, enterpriseName string) (IPAllowListForInstalledAppsEnabledSettingValue, error) {
+	return c.GetOwnerIPAllowListForInstalledAppsEnabledSetting(ctx, EnterpriseOwner(enterpriseName))
 }
@@ -70,6 +70,26 @@ const someGetEnterpriseIPAllowListEntriesResponse = `{
     }
 }`
 
+const getEnterpriseIPAllowListEnabledSettingResponseTemplate = `{
+    "data": {
+        "enterprise": {
+            "ownerInfo": {
+                "ipAllowListEnabledSetting": "%s"
+            }
+        }
+    }
+}`
+
+const getEnterpriseIPAllowListForInstalledAppsEnabledSettingResponseTemplate = `{
+    "data": {
+        "enterprise": {
+            "ownerInfo": {
+                "ipAllowListForInstalledAppsEnabledSetting": "%s"
+            }
+        }
+    }
+}`
+
 func TestGetEnterpriseID(t *testing.T) {
 	// given
 	expectedEnterpriseID := "abc123"
@@ -84,6 +104,25 @@ func TestGetEnterpriseID(t *testing.T) {
 	assert.Equal(t, expectedEnterpriseID, retrievedEnterpriseID)
 }
 
+func TestGetEnterpriseIDIsCachedPerEnterpriseName(t *testing.T) {
+	// given
+	expectedEnterpriseID := "abc123"
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(getEnterpriseIDResponseWith(expectedEnterpriseID), getEnterpriseIDResponseWith("other-id"))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	_, _ = client.GetEnterpriseID(context.TODO(), "some enterprise")
+	retrievedEnterpriseID, err := client.GetEnterpriseID(context.TODO(), "some enterprise")
+	otherEnterpriseID, otherErr := client.GetEnterpriseID(context.TODO(), "other enterprise")
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, otherErr)
+	assert.Equal(t, expectedEnterpriseID, retrievedEnterpriseID)
+	assert.Equal(t, "other-id", otherEnterpriseID)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
 func TestGetEnterpriseIDWithFailingServer(t *testing.T) {
 	// given
 	expectedStatusCode := http.StatusInternalServerError
@@ -142,7 +181,7 @@ func TestGetEnterpriseIPAllowListEntriesWithPagedResponseOneEntryPerPage(t *test
 			client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
 
 			// when
-			entries, err := client.getEnterpriseIPAllowListEntries(context.TODO(), "some enterprise")
+			entries, err := client.GetEnterpriseIPAllowListEntries(context.TODO(), "some enterprise")
 
 			// then
 			assert.NoError(t, err)
@@ -212,6 +251,40 @@ func TestGetEnterpriseIPAllowListEntriesWithEntriesCachingDoesNotExecuteCallsCon
 	}
 }
 
+func TestGetEnterpriseIPAllowListEnabledSetting(t *testing.T) {
+	for _, expectedValue := range []IPAllowListEnabledSettingValue{IPAllowListEnabledSettingEnabled, IPAllowListEnabledSettingDisabled} {
+		t.Run(string(expectedValue), func(t *testing.T) {
+			// given
+			gitHubGraphQLAPIMock := serverReturning(fmt.Sprintf(getEnterpriseIPAllowListEnabledSettingResponseTemplate, expectedValue))
+			client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+			// when
+			value, err := client.GetEnterpriseIPAllowListEnabledSetting(context.TODO(), "some enterprise")
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, expectedValue, value)
+		})
+	}
+}
+
+func TestGetEnterpriseIPAllowListForInstalledAppsEnabledSetting(t *testing.T) {
+	for _, expectedValue := range []IPAllowListForInstalledAppsEnabledSettingValue{IPAllowListForInstalledAppsEnabledSettingEnabled, IPAllowListForInstalledAppsEnabledSettingDisabled} {
+		t.Run(string(expectedValue), func(t *testing.T) {
+			// given
+			gitHubGraphQLAPIMock := serverReturning(fmt.Sprintf(getEnterpriseIPAllowListForInstalledAppsEnabledSettingResponseTemplate, expectedValue))
+			client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+			// when
+			value, err := client.GetEnterpriseIPAllowListForInstalledAppsEnabledSetting(context.TODO(), "some enterprise")
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, expectedValue, value)
+		})
+	}
+}
+
 func getEnterpriseIDResponseWith(expectedEnterpriseID string) string {
 	return fmt.Sprintf(getEnterpriseIDResponseTemplate, expectedEnterpriseID)
 }
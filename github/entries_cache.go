@@ -0,0 +1,342 @@
+package github
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ipAllowListEntryOwner is an organization, an enterprise or an app owning an entry returned by a mutation.
+type ipAllowListEntryOwner struct {
+	Typename string `json:"__typename"`
+	Login    string `json:"login"`
+	Slug     string `json:"slug"`
+	ID       string `json:"id"`
+}
+
+// owner returns the Owner of an entry, or false if it is unknown.
+// Without a __typename, a login identifies an organization and a slug an enterprise.
+func (o *ipAllowListEntryOwner) owner() (Owner, bool) {
+	if o == nil {
+		return Owner{}, false
+	}
+	switch {
+	case o.Typename == "Organization" && o.Login != "":
+		return OrganizationOwner(o.Login), true
+	case o.Typename == "Enterprise" && o.Slug != "":
+		return EnterpriseOwner(o.Slug), true
+	case o.Typename == "App" && o.ID != "":
+		return AppOwner(o.ID), true
+	case o.Typename == "" && o.Login != "":
+		return OrganizationOwner(o.Login), true
+	case o.Typename == "" && o.Slug != "":
+		return EnterpriseOwner(o.Slug), true
+	}
+	return Owner{}, false
+}
+
+type cachedEntries struct {
+	Entries   []*IPAllowListEntry `json:"entries"`
+	FetchedAt time.Time           `json:"fetchedAt"`
+
+	index *entriesIndex
+}
+
+func newCachedEntries(entries []*IPAllowListEntry, fetchedAt time.Time) cachedEntries {
+	return cachedEntries{Entries: entries, FetchedAt: fetchedAt, index: newEntriesIndex(entries)}
+}
+
+// entriesCache keeps entries of owners of one kind in memory and optionally on disk.
+// Entries older than ttl are fetched again, unless ttl is 0 in which case in memory entries never expire.
+// The mutex guards only the in memory state, so fetching entries of one owner never blocks reading or fetching entries of another owner.
+type entriesCache struct {
+	mutex    *sync.Mutex
+	entries  map[string]cachedEntries
+	fetches  map[string]*entriesFetch
+	ttl      time.Duration
+	disk     *entriesDiskCache
+	now      func() time.Time
+	modified uint64
+}
+
+// entriesFetch is a fetch of entries of an owner in progress, whose result is shared by all callers missing the cache meanwhile.
+type entriesFetch struct {
+	done   chan struct{}
+	cached cachedEntries
+	err    error
+}
+
+func newEntriesCache(ttl time.Duration, disk *entriesDiskCache) *entriesCache {
+	return &entriesCache{
+		mutex:   &sync.Mutex{},
+		entries: make(map[string]cachedEntries, 8),
+		fetches: make(map[string]*entriesFetch, 8),
+		ttl:     ttl,
+		disk:    disk,
+		now:     time.Now,
+	}
+}
+
+func (c *entriesCache) fresh(cached cachedEntries) bool {
+	return c.ttl <= 0 || c.now().Sub(cached.FetchedAt) < c.ttl
+}
+
+// peek returns cached entries of an owner if they are fresh, without fetching them.
+func (c *entriesCache) peek(owner string) ([]*IPAllowListEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cached, ok := c.entries[owner]
+	if !ok || !c.fresh(cached) {
+		return nil, false
+	}
+	return cached.Entries, true
+}
+
+// getOrFetch returns cached entries of an owner, fetching them if they are not cached or expired.
+func (c *entriesCache) getOrFetch(ctx context.Context, owner string, fetch func(context.Context, string) ([]*IPAllowListEntry, error)) ([]*IPAllowListEntry, error) {
+	cached, err := c.getOrFetchCached(ctx, owner, fetch)
+	return cached.Entries, err
+}
+
+// getOrFetchIndex returns an index of cached entries of an owner, fetching them if they are not cached or expired.
+func (c *entriesCache) getOrFetchIndex(ctx context.Context, owner string, fetch func(context.Context, string) ([]*IPAllowListEntry, error)) (*entriesIndex, error) {
+	cached, err := c.getOrFetchCached(ctx, owner, fetch)
+	if err != nil {
+		return nil, err
+	}
+	return cached.index, nil
+}
+
+// getOrFetchCached returns cached entries of an owner along with their index, fetching them if they are not cached or expired.
+// Concurrent calls for the same owner share a single call to GitHub's GraphQL API, made with the context of the first of them,
+// while calls for different owners fetch in parallel. Callers waiting for a fetch of another caller stop waiting once their ctx is done.
+func (c *entriesCache) getOrFetchCached(ctx context.Context, owner string, fetch func(context.Context, string) ([]*IPAllowListEntry, error)) (cachedEntries, error) {
+	c.mutex.Lock()
+	cached, ok := c.entries[owner]
+	if ok && c.fresh(cached) {
+		c.mutex.Unlock()
+		return cached, nil
+	}
+	if f, ok := c.fetches[owner]; ok {
+		c.mutex.Unlock()
+		select {
+		case <-f.done:
+			return f.cached, f.err
+		case <-ctx.Done():
+			return cachedEntries{}, ctx.Err()
+		}
+	}
+	f := &entriesFetch{done: make(chan struct{})}
+	c.fetches[owner] = f
+	modified := c.modified
+	c.mutex.Unlock()
+
+	f.cached, f.err = c.fetch(ctx, owner, modified, fetch)
+
+	c.mutex.Lock()
+	delete(c.fetches, owner)
+	c.mutex.Unlock()
+	close(f.done)
+	return f.cached, f.err
+}
+
+// fetch loads entries of an owner from disk or fetches them, and caches them unless the cache was modified since modified was read.
+// Entries fetched meanwhile a mutation updated the cache might miss the mutation's result, so they are returned but not cached.
+// The on disk cache is locked during a fetch so that other processes sharing it wait for its result instead of fetching the same entries.
+func (c *entriesCache) fetch(ctx context.Context, owner string, modified uint64, fetch func(context.Context, string) ([]*IPAllowListEntry, error)) (cachedEntries, error) {
+	if c.disk != nil {
+		unlock, err := c.disk.lock(ctx, owner)
+		if err != nil {
+			return cachedEntries{}, err
+		}
+		defer unlock()
+
+		cached, ok := c.disk.load(owner)
+		if ok && c.fresh(cached) {
+			cached = newCachedEntries(cached.Entries, cached.FetchedAt)
+			c.mutex.Lock()
+			if c.modified == modified {
+				c.entries[owner] = cached
+			}
+			c.mutex.Unlock()
+			return cached, nil
+		}
+	}
+
+	entries, err := fetch(ctx, owner)
+	if err != nil {
+		return cachedEntries{Entries: entries}, err
+	}
+
+	cached := newCachedEntries(entries, c.now())
+	c.mutex.Lock()
+	store := c.modified == modified
+	if store {
+		c.entries[owner] = cached
+	}
+	c.mutex.Unlock()
+	if store && c.disk != nil {
+		c.disk.store(owner, cached)
+	}
+	return cached, nil
+}
+
+// update sets cached entries of an owner to the result of update.
+// Owners without cached entries are left uncached, and their entries cached on disk by other processes are dropped as they are stale now.
+func (c *entriesCache) update(ctx context.Context, owner string, update func([]*IPAllowListEntry) []*IPAllowListEntry) {
+	c.mutex.Lock()
+	c.modified++
+	cached, ok := c.entries[owner]
+	if ok {
+		c.entries[owner] = newCachedEntries(update(cached.Entries), cached.FetchedAt)
+	}
+	c.mutex.Unlock()
+
+	c.persist(ctx, owner)
+}
+
+// replace replaces an entry with a given entryID in cached entries of all owners, or removes it if entry is nil.
+// Cached slices are never modified in place as they might have been returned to callers.
+// Reports whether the entry was cached.
+func (c *entriesCache) replace(ctx context.Context, entryID string, entry *IPAllowListEntry) bool {
+	c.mutex.Lock()
+	c.modified++
+	var owners []string
+	for owner, cached := range c.entries {
+		if cached.index.entryByID(entryID) == nil {
+			continue
+		}
+		owners = append(owners, owner)
+		replaced := make([]*IPAllowListEntry, 0, len(cached.Entries))
+		for _, e := range cached.Entries {
+			switch {
+			case e == nil || e.ID != entryID:
+				replaced = append(replaced, e)
+			case entry != nil:
+				replaced = append(replaced, entry)
+			}
+		}
+		c.entries[owner] = newCachedEntries(replaced, cached.FetchedAt)
+	}
+	c.mutex.Unlock()
+
+	for _, owner := range owners {
+		c.persist(ctx, owner)
+	}
+	return len(owners) > 0
+}
+
+// persist stores in memory entries of an owner on disk, or removes them from disk if they are not cached in memory.
+// Entries are read while the owner's disk lock is held, so concurrent calls never leave older entries on disk.
+func (c *entriesCache) persist(ctx context.Context, owner string) {
+	if c.disk == nil {
+		return
+	}
+	unlock, err := c.disk.lock(ctx, owner)
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	c.mutex.Lock()
+	cached, ok := c.entries[owner]
+	c.mutex.Unlock()
+	if ok {
+		c.disk.store(owner, cached)
+	} else {
+		c.disk.remove(owner)
+	}
+}
+
+func (c *entriesCache) invalidate(ctx context.Context, owner string) {
+	c.mutex.Lock()
+	c.modified++
+	delete(c.entries, owner)
+	c.mutex.Unlock()
+
+	c.persist(ctx, owner)
+}
+
+func (c *entriesCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.modified++
+	c.entries = make(map[string]cachedEntries, 8)
+	if c.disk != nil {
+		c.disk.removeAll()
+	}
+}
+
+func (c *entriesCache) clearDisk() {
+	if c.disk == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.disk.removeAll()
+}
+
+// InvalidateCache drops cached entries of a given owner, including ones cached on disk.
+// The next entry listing function call for the owner fetches entries from GitHub's GraphQL API.
+func (c *Client) InvalidateCache(owner Owner) {
+	if !c.cacheEntries {
+		return
+	}
+	cache, err := c.entriesCache(owner)
+	if err != nil {
+		return
+	}
+	cache.invalidate(context.Background(), owner.Name)
+}
+
+// cacheCreatedEntry appends a created entry to cached entries of its owner.
+// If the owner is unknown, all cached entries are dropped as any of them might be stale.
+func (c *Client) cacheCreatedEntry(ctx context.Context, owner *ipAllowListEntryOwner, entry IPAllowListEntry) {
+	if !c.cacheEntries {
+		return
+	}
+
+	appendEntry := func(entries []*IPAllowListEntry) []*IPAllowListEntry {
+		return append(entries[:len(entries):len(entries)], &entry)
+	}
+	if o, ok := owner.owner(); ok {
+		c.entriesCaches[o.Kind].update(ctx, o.Name, appendEntry)
+		return
+	}
+	for _, kind := range ownerKinds {
+		c.entriesCaches[kind].clear()
+	}
+}
+
+// cacheUpdatedEntry replaces a cached entry having the same ID as the updated entry.
+func (c *Client) cacheUpdatedEntry(ctx context.Context, entry IPAllowListEntry) {
+	c.replaceCachedEntry(ctx, entry.ID, &entry)
+}
+
+// cacheDeletedEntry removes a cached entry with a given entryID.
+func (c *Client) cacheDeletedEntry(ctx context.Context, entryID string) {
+	c.replaceCachedEntry(ctx, entryID, nil)
+}
+
+// replaceCachedEntry replaces an entry with a given entryID in cached entries of all owners, or removes it if entry is nil.
+// Entries cached on disk by other processes are dropped if the entry's owner is unknown to this client.
+func (c *Client) replaceCachedEntry(ctx context.Context, entryID string, entry *IPAllowListEntry) {
+	if !c.cacheEntries {
+		return
+	}
+
+	found := false
+	for _, kind := range ownerKinds {
+		if c.entriesCaches[kind].replace(ctx, entryID, entry) {
+			found = true
+		}
+	}
+	if !found {
+		for _, kind := range ownerKinds {
+			c.entriesCaches[kind].clearDisk()
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const createEntryResponseWithOwnerTemplate = `
+{
+    "data": {
+        "createIpAllowListEntry": {
+            "ipAllowListEntry": {
+                "id": "%s",
+                "createdAt": "%s",
+                "updatedAt": "%s",
+                "allowListValue": "%s",
+                "isActive": %t,
+                "name": "%s",
+                "owner": %s
+            }
+        }
+    }
+}`
+
+var someCachedEntry = IPAllowListEntry{
+	ID:             "cached-id",
+	CreatedAt:      truncateToGitHubPrecision(time.Now()),
+	UpdatedAt:      truncateToGitHubPrecision(time.Now()),
+	AllowListValue: "1.2.3.4/32",
+	IsActive:       true,
+	Name:           "cached",
+}
+
+var someCreatedEntry = IPAllowListEntry{
+	ID:             "created-id",
+	CreatedAt:      truncateToGitHubPrecision(time.Now()),
+	UpdatedAt:      truncateToGitHubPrecision(time.Now()),
+	AllowListValue: "5.6.7.8/32",
+	IsActive:       false,
+	Name:           "created",
+}
+
+func TestCreateIPAllowListEntryAddsEntryToOrganizationCache(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		createEntryResponseWithOwner(someCreatedEntry, `{"login": "some organization"}`),
+	)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	cachedEntries, _ := client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// when
+	_, err := client.CreateIPAllowListEntry(context.TODO(), "some owner", someCreatedEntry.Name, someCreatedEntry.AllowListValue, someCreatedEntry.IsActive)
+	entries, _ := client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCachedEntry, &someCreatedEntry}, entries)
+	assert.Equal(t, []*IPAllowListEntry{&someCachedEntry}, cachedEntries)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
+func TestCreateIPAllowListEntryAddsEntryToEnterpriseCache(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getEnterpriseIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		createEntryResponseWithOwner(someCreatedEntry, `{"slug": "some enterprise"}`),
+	)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	_, _ = client.GetEnterpriseIPAllowListEntries(context.TODO(), "some enterprise")
+
+	// when
+	_, err := client.CreateIPAllowListEntry(context.TODO(), "some owner", someCreatedEntry.Name, someCreatedEntry.AllowListValue, someCreatedEntry.IsActive)
+	entries, _ := client.GetEnterpriseIPAllowListEntries(context.TODO(), "some enterprise")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCachedEntry, &someCreatedEntry}, entries)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
+func TestCreateIPAllowListEntryOfUnknownOwnerInvalidatesCache(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		createEntryResponseWithOwner(someCreatedEntry, `{}`),
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCreatedEntry),
+	)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	_, _ = client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// when
+	_, err := client.CreateIPAllowListEntry(context.TODO(), "some owner", someCreatedEntry.Name, someCreatedEntry.AllowListValue, someCreatedEntry.IsActive)
+	entries, _ := client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCreatedEntry}, entries)
+	assert.Equal(t, int64(3), receivedRequests.Load())
+}
+
+func TestUpdateIPAllowListEntryReplacesCachedEntry(t *testing.T) {
+	// given
+	updatedEntry := someCachedEntry
+	updatedEntry.Name = "updated"
+	updatedEntry.IsActive = false
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		updateEntryResponseWith(updatedEntry),
+	)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	cachedEntries, _ := client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// when
+	_, err := client.UpdateIPAllowListEntry(context.TODO(), updatedEntry.ID, someIPAllowListEntryParameters)
+	entries, _ := client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&updatedEntry}, entries)
+	assert.Equal(t, []*IPAllowListEntry{&someCachedEntry}, cachedEntries)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
+func TestDeleteIPAllowListEntryRemovesCachedEntry(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getEnterpriseIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		deleteEntryResponseWith(someCachedEntry.ID),
+	)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	cachedEntries, _ := client.GetEnterpriseIPAllowListEntries(context.TODO(), "some enterprise")
+
+	// when
+	_, err := client.DeleteIPAllowListEntry(context.TODO(), someCachedEntry.ID)
+	entries, _ := client.GetEnterpriseIPAllowListEntries(context.TODO(), "some enterprise")
+
+	// then
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Equal(t, []*IPAllowListEntry{&someCachedEntry}, cachedEntries)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
+func TestDeleteIPAllowListEntryOfMissingEntryRemovesCachedEntry(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getEnterpriseIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		deleteEntryResponseForMissingEntry,
+	)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	_, _ = client.GetEnterpriseIPAllowListEntries(context.TODO(), "some enterprise")
+
+	// when
+	_, err := client.DeleteIPAllowListEntry(context.TODO(), someCachedEntry.ID)
+	entries, _ := client.GetEnterpriseIPAllowListEntries(context.TODO(), "some enterprise")
+
+	// then
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Empty(t, entries)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
+func TestInvalidateCache(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCreatedEntry),
+	)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	_, _ = client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// when
+	client.InvalidateCache(OrganizationOwner("some organization"))
+	entries, err := client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCreatedEntry}, entries)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
+func TestInvalidateCacheKeepsEntriesOfOwnersOfOtherKinds(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+	)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	_, _ = client.GetOrganizationIPAllowListEntries(context.TODO(), "some-name")
+
+	// when
+	client.InvalidateCache(AppOwner("some-name"))
+	entries, err := client.GetOrganizationIPAllowListEntries(context.TODO(), "some-name")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCachedEntry}, entries)
+	assert.Equal(t, int64(1), receivedRequests.Load())
+}
+
+func TestInvalidateCacheWithoutEntriesCaching(t *testing.T) {
+	// given
+	client := NewGitHubClient(nil, WithoutEntriesCaching())
+
+	// then
+	assert.NotPanics(t, func() {
+		client.InvalidateCache(OrganizationOwner("some organization"))
+	})
+}
+
+func createEntryResponseWithOwner(expectedEntry IPAllowListEntry, owner string) string {
+	return fmt.Sprintf(createEntryResponseWithOwnerTemplate, expectedEntry.ID, expectedEntry.CreatedAt.Format(gitHubTimeFormat), expectedEntry.UpdatedAt.Format(gitHubTimeFormat), expectedEntry.AllowListValue, expectedEntry.IsActive, expectedEntry.Name, owner)
+}
+
+func TestEntriesCacheFetchesEntriesOfDifferentOwnersInParallel(t *testing.T) {
+	// given
+	cache := newEntriesCache(0, nil)
+	var started sync.WaitGroup
+	started.Add(2)
+	fetch := func(context.Context, string) ([]*IPAllowListEntry, error) {
+		started.Done()
+		// Returns only once fetches of both owners are in progress.
+		started.Wait()
+		return []*IPAllowListEntry{&someCachedEntry}, nil
+	}
+
+	// when
+	results := make(chan error, 2)
+	for _, owner := range []string{"some organization", "another organization"} {
+		go func(owner string) {
+			_, err := cache.getOrFetch(context.TODO(), owner, fetch)
+			results <- err
+		}(owner)
+	}
+
+	// then
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-results:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			assert.Fail(t, "Fetches of different owners did not run in parallel.")
+			return
+		}
+	}
+}
+
+func TestEntriesCacheSharesFetchOfTheSameOwner(t *testing.T) {
+	// given
+	cache := newEntriesCache(0, nil)
+	release := make(chan struct{})
+	var fetches atomic.Int64
+	fetch := func(context.Context, string) ([]*IPAllowListEntry, error) {
+		fetches.Add(1)
+		<-release
+		return []*IPAllowListEntry{&someCachedEntry}, nil
+	}
+
+	// when
+	var done sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			entries, err := cache.getOrFetch(context.TODO(), "some organization", fetch)
+			assert.NoError(t, err)
+			assert.Equal(t, []*IPAllowListEntry{&someCachedEntry}, entries)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	// then
+	assert.Equal(t, int64(1), fetches.Load())
+}
+
+func TestEntriesCacheStopsWaitingForAFetchOnceContextIsDone(t *testing.T) {
+	// given
+	cache := newEntriesCache(0, nil)
+	release := make(chan struct{})
+	defer close(release)
+	fetching := make(chan struct{})
+	go func() {
+		_, _ = cache.getOrFetch(context.TODO(), "some organization", func(context.Context, string) ([]*IPAllowListEntry, error) {
+			close(fetching)
+			<-release
+			return nil, nil
+		})
+	}()
+	<-fetching
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+
+	// when
+	_, err := cache.getOrFetch(ctx, "some organization", func(context.Context, string) ([]*IPAllowListEntry, error) {
+		assert.Fail(t, "A fetch of the same owner was started while another one was in progress.")
+		return nil, nil
+	})
+
+	// then
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestEntriesCacheDoesNotCacheEntriesFetchedWhileCacheWasModified(t *testing.T) {
+	// given
+	cache := newEntriesCache(0, nil)
+	fetch := func(context.Context, string) ([]*IPAllowListEntry, error) {
+		// A mutation invalidates the owner's entries while they are being fetched.
+		cache.invalidate(context.TODO(), "some organization")
+		return []*IPAllowListEntry{&someCachedEntry}, nil
+	}
+
+	// when
+	entries, err := cache.getOrFetch(context.TODO(), "some organization", fetch)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCachedEntry}, entries)
+	_, cached := cache.entries["some organization"]
+	assert.False(t, cached)
+}
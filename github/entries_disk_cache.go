@@ -0,0 +1,176 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	diskCacheLockRetryInterval = 50 * time.Millisecond
+	// diskCacheStaleLockAge is an age after which a lock is assumed to be left behind by a process which did not release it, e.g. it was killed.
+	diskCacheStaleLockAge = 2 * time.Minute
+	// diskCacheLockRefreshInterval is how often a held lock is touched, so that it never gets stale while entries are fetched,
+	// which can take longer than diskCacheStaleLockAge when waiting for rate limits.
+	diskCacheLockRefreshInterval = diskCacheStaleLockAge / 4
+)
+
+// entriesDiskCache stores entries as JSON files in a directory shared between processes, e.g. `terraform plan` and `terraform apply`.
+// File names are derived from GitHub's GraphQL API URL, an owner kind and an owner name, so one directory can be shared by clients of different GitHub instances.
+// Failures to read or write files are treated as cache misses, as the cache is only an optimisation.
+type entriesDiskCache struct {
+	dir                 string
+	prefix              string
+	lockRefreshInterval time.Duration
+}
+
+func newEntriesDiskCache(dir string, apiURL string, kind string) *entriesDiskCache {
+	return &entriesDiskCache{
+		dir:                 dir,
+		prefix:              hash(apiURL + "\n" + kind)[:16],
+		lockRefreshInterval: diskCacheLockRefreshInterval,
+	}
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func (d *entriesDiskCache) path(owner string) string {
+	return filepath.Join(d.dir, d.prefix+"-"+hash(owner)[:32]+".json")
+}
+
+// lock creates a lock file next to an owner's cache file, waiting until other processes remove theirs or ctx is done.
+// The lock file holds a random token identifying its holder, and is touched while it is held so that other processes do not take it for a stale one.
+// Returns a function releasing the lock, which removes the lock file unless another process took it over meanwhile.
+// Locking is skipped if the lock file cannot be created for reasons other than it already exists.
+func (d *entriesDiskCache) lock(ctx context.Context, owner string) (func(), error) {
+	lockPath := d.path(owner) + ".lock"
+	token := make([]byte, 16)
+	_, err := rand.Read(token)
+	if err != nil {
+		return func() {}, nil
+	}
+	token = []byte(hex.EncodeToString(token))
+	for {
+		err := os.MkdirAll(d.dir, 0o700)
+		if err != nil {
+			return func() {}, nil
+		}
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_, writeErr := f.Write(token)
+			closeErr := f.Close()
+			if writeErr != nil || closeErr != nil {
+				_ = os.Remove(lockPath)
+				return func() {}, nil
+			}
+			return d.holdLock(lockPath, token), nil
+		}
+		if !os.IsExist(err) {
+			return func() {}, nil
+		}
+
+		info, err := os.Stat(lockPath)
+		if err == nil && time.Since(info.ModTime()) > diskCacheStaleLockAge {
+			_ = os.Remove(lockPath)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "cannot acquire entries cache lock "+lockPath)
+		case <-time.After(diskCacheLockRetryInterval):
+		}
+	}
+}
+
+// holdLock touches a lock file until the returned function is called, which then removes the lock file if it still holds token.
+func (d *entriesDiskCache) holdLock(lockPath string, token []byte) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(d.lockRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				now := time.Now()
+				_ = os.Chtimes(lockPath, now, now)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		b, err := os.ReadFile(lockPath)
+		if err == nil && bytes.Equal(b, token) {
+			_ = os.Remove(lockPath)
+		}
+	}
+}
+
+// load must be called with the owner's lock held.
+func (d *entriesDiskCache) load(owner string) (cachedEntries, bool) {
+	b, err := os.ReadFile(d.path(owner))
+	if err != nil {
+		return cachedEntries{}, false
+	}
+	var cached cachedEntries
+	err = json.Unmarshal(b, &cached)
+	if err != nil {
+		return cachedEntries{}, false
+	}
+	return cached, true
+}
+
+// store must be called with the owner's lock held. The file is replaced atomically so readers never see a partially written one.
+func (d *entriesDiskCache) store(owner string, cached cachedEntries) {
+	b, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+	f, err := os.CreateTemp(d.dir, d.prefix+"-*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(b)
+	closeErr := f.Close()
+	if err != nil || closeErr != nil {
+		_ = os.Remove(f.Name())
+		return
+	}
+	err = os.Rename(f.Name(), d.path(owner))
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+}
+
+// remove must be called with the owner's lock held.
+func (d *entriesDiskCache) remove(owner string) {
+	_ = os.Remove(d.path(owner))
+}
+
+// removeAll removes cache files of all owners. Lock files are left as they are.
+func (d *entriesDiskCache) removeAll() {
+	paths, err := filepath.Glob(filepath.Join(d.dir, d.prefix+"-*.json"))
+	if err != nil {
+		return
+	}
+	for _, p := range paths {
+		_ = os.Remove(p)
+	}
+}
@@ -0,0 +1,234 @@
+package github
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrganizationIPAllowListEntriesWithEntriesCacheTTLRefetchesExpiredEntries(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCreatedEntry),
+	)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithEntriesCacheTTL(time.Minute))
+	now := time.Now()
+	client.entriesCaches[OwnerKindOrganization].now = func() time.Time { return now }
+	_, _ = client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// when
+	now = now.Add(59 * time.Second)
+	cachedEntries, _ := client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+	now = now.Add(time.Second)
+	entries, err := client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCachedEntry}, cachedEntries)
+	assert.Equal(t, []*IPAllowListEntry{&someCreatedEntry}, entries)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
+func TestGetOrganizationIPAllowListEntriesWithEntriesDiskCacheSharesEntriesBetweenClients(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry))
+	dir := t.TempDir()
+	newClient := func() *Client {
+		return NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithEntriesCacheTTL(time.Minute), WithEntriesDiskCache(dir))
+	}
+	_, _ = newClient().GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// when
+	entries, err := newClient().GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCachedEntry}, entries)
+	assert.Equal(t, int64(1), receivedRequests.Load())
+}
+
+func TestGetOrganizationIPAllowListEntriesWithEntriesDiskCacheRefetchesExpiredEntries(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCreatedEntry),
+	)
+	dir := t.TempDir()
+	_, _ = NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithEntriesCacheTTL(time.Minute), WithEntriesDiskCache(dir)).
+		GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithEntriesCacheTTL(time.Minute), WithEntriesDiskCache(dir))
+	client.entriesCaches[OwnerKindOrganization].now = func() time.Time { return time.Now().Add(time.Minute) }
+
+	// when
+	entries, err := client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCreatedEntry}, entries)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
+func TestGetIPAllowListEntriesWithEntriesDiskCacheIsKeyedByAPIURLAndOwner(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		getEnterpriseIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+	)
+	dir := t.TempDir()
+	newClient := func(url string) *Client {
+		return NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(url), WithEntriesCacheTTL(time.Minute), WithEntriesDiskCache(dir))
+	}
+	_, _ = newClient(gitHubGraphQLAPIMock.URL).GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// when
+	_, _ = newClient(gitHubGraphQLAPIMock.URL).GetOrganizationIPAllowListEntries(context.TODO(), "other organization")
+	_, _ = newClient(gitHubGraphQLAPIMock.URL).GetEnterpriseIPAllowListEntries(context.TODO(), "some organization")
+	_, err := newClient(gitHubGraphQLAPIMock.URL+"/other").GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), receivedRequests.Load())
+}
+
+func TestGetOrganizationIPAllowListEntriesWithEntriesDiskCacheWithoutTTLDoesNotShareEntries(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+	)
+	dir := t.TempDir()
+	newClient := func() *Client {
+		return NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithEntriesDiskCache(dir))
+	}
+	_, _ = newClient().GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// when
+	_, err := newClient().GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
+func TestUpdateIPAllowListEntryWithEntriesDiskCacheWritesThrough(t *testing.T) {
+	// given
+	updatedEntry := someCachedEntry
+	updatedEntry.Name = "updated"
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		updateEntryResponseWith(updatedEntry),
+	)
+	dir := t.TempDir()
+	newClient := func() *Client {
+		return NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithEntriesCacheTTL(time.Minute), WithEntriesDiskCache(dir))
+	}
+	client := newClient()
+	_, _ = client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// when
+	_, _ = client.UpdateIPAllowListEntry(context.TODO(), updatedEntry.ID, someIPAllowListEntryParameters)
+	entries, err := newClient().GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&updatedEntry}, entries)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
+func TestDeleteIPAllowListEntryOfUnknownOwnerWithEntriesDiskCacheDropsEntriesCachedOnDisk(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		deleteEntryResponseWith(someCachedEntry.ID),
+		getOrganizationIPAllowListEntriesResponseLastPageWith(someCreatedEntry),
+	)
+	dir := t.TempDir()
+	newClient := func() *Client {
+		return NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithEntriesCacheTTL(time.Minute), WithEntriesDiskCache(dir))
+	}
+	_, _ = newClient().GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// when
+	_, _ = newClient().DeleteIPAllowListEntry(context.TODO(), someCachedEntry.ID)
+	entries, err := newClient().GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCreatedEntry}, entries)
+	assert.Equal(t, int64(3), receivedRequests.Load())
+}
+
+func TestEntriesDiskCacheLockWaitsForOtherProcesses(t *testing.T) {
+	// given
+	disk := newEntriesDiskCache(t.TempDir(), "some url", "organization")
+	unlock, err := disk.lock(context.TODO(), "some organization")
+	assert.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.TODO(), 200*time.Millisecond)
+	defer cancel()
+
+	// when
+	_, err = disk.lock(ctx, "some organization")
+
+	// then
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// and when
+	unlock()
+	unlock, err = disk.lock(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	unlock()
+}
+
+func TestEntriesDiskCacheLockRemovesStaleLocks(t *testing.T) {
+	// given
+	disk := newEntriesDiskCache(t.TempDir(), "some url", "organization")
+	staleUnlock, err := disk.lock(context.TODO(), "some organization")
+	assert.NoError(t, err)
+	lockPath := disk.path("some organization") + ".lock"
+	staleTime := time.Now().Add(-diskCacheStaleLockAge - time.Second)
+	assert.NoError(t, os.Chtimes(lockPath, staleTime, staleTime))
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	// when
+	unlock, err := disk.lock(ctx, "some organization")
+
+	// then
+	assert.NoError(t, err)
+
+	// and when
+	staleUnlock()
+
+	// then
+	assert.FileExists(t, lockPath)
+	unlock()
+	assert.NoFileExists(t, lockPath)
+}
+
+func TestEntriesDiskCacheLockIsRefreshedWhileHeld(t *testing.T) {
+	// given
+	disk := newEntriesDiskCache(t.TempDir(), "some url", "organization")
+	disk.lockRefreshInterval = 10 * time.Millisecond
+	unlock, err := disk.lock(context.TODO(), "some organization")
+	assert.NoError(t, err)
+	defer unlock()
+	lockPath := disk.path("some organization") + ".lock"
+	staleTime := time.Now().Add(-diskCacheStaleLockAge - time.Second)
+	assert.NoError(t, os.Chtimes(lockPath, staleTime, staleTime))
+
+	// when
+	time.Sleep(100 * time.Millisecond)
+
+	// then
+	info, err := os.Stat(lockPath)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(info.ModTime()), diskCacheStaleLockAge)
+}
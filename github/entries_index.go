@@ -0,0 +1,36 @@
+package github
+
+// entriesIndex indexes entries of an owner by node ID and by normalized CIDR, so that looking up an entry does not scan all entries.
+// It is built once per listing or cache update and never modified afterwards.
+type entriesIndex struct {
+	byID   map[string]*IPAllowListEntry
+	byCIDR map[CIDR][]*IPAllowListEntry
+}
+
+func newEntriesIndex(entries []*IPAllowListEntry) *entriesIndex {
+	index := &entriesIndex{
+		byID:   make(map[string]*IPAllowListEntry, len(entries)),
+		byCIDR: make(map[CIDR][]*IPAllowListEntry, len(entries)),
+	}
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		if _, ok := index.byID[e.ID]; !ok {
+			index.byID[e.ID] = e
+		}
+		value := NormalizedCIDR(e.AllowListValue)
+		index.byCIDR[value] = append(index.byCIDR[value], e)
+	}
+	return index
+}
+
+// entryByID returns the first entry with a given entryID, or nil if there is none.
+func (i *entriesIndex) entryByID(entryID string) *IPAllowListEntry {
+	return i.byID[entryID]
+}
+
+// entriesByCIDR returns entries with a value equal to a given value regardless of its notation, in the order they were listed.
+func (i *entriesIndex) entriesByCIDR(value CIDR) []*IPAllowListEntry {
+	return i.byCIDR[NormalizedCIDR(value)]
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntriesIndex(t *testing.T) {
+	// given
+	first := &IPAllowListEntry{ID: "id-1", AllowListValue: "1.2.3.4"}
+	second := &IPAllowListEntry{ID: "id-2", AllowListValue: "1.2.3.4/32"}
+	invalid := &IPAllowListEntry{ID: "id-3", AllowListValue: "invalid"}
+	duplicate := &IPAllowListEntry{ID: "id-1", AllowListValue: "10.0.0.0/8"}
+
+	// when
+	index := newEntriesIndex([]*IPAllowListEntry{nil, first, second, invalid, duplicate})
+
+	// then
+	assert.Same(t, first, index.entryByID("id-1"))
+	assert.Same(t, invalid, index.entryByID("id-3"))
+	assert.Nil(t, index.entryByID("missing"))
+	assert.Equal(t, []*IPAllowListEntry{first, second}, index.entriesByCIDR("1.2.3.4/32"))
+	assert.Equal(t, []*IPAllowListEntry{first, second}, index.entriesByCIDR("1.2.3.4"))
+	assert.Equal(t, []*IPAllowListEntry{invalid}, index.entriesByCIDR("invalid"))
+	assert.Empty(t, index.entriesByCIDR("5.6.7.8"))
+}
+
+func TestGetOrganizationIPAllowListEntryByID(t *testing.T) {
+	for name, opts := range map[string][]ClientOption{"with caching": nil, "without caching": {WithoutEntriesCaching()}} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			gitHubGraphQLAPIMock := serverReturning(getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry))
+			client := NewAuthenticatedGitHubClient(context.TODO(), "", append(opts, WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))...)
+
+			// when
+			entry, err := client.GetOrganizationIPAllowListEntryByID(context.TODO(), "some organization", someCachedEntry.ID)
+			_, missingErr := client.GetOrganizationIPAllowListEntryByID(context.TODO(), "some organization", "missing-id")
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, &someCachedEntry, entry)
+			assert.ErrorIs(t, missingErr, ErrNotFound)
+		})
+	}
+}
+
+const getOrganizationNotFoundResponse = `{
+    "data": {"organization": null},
+    "errors": [
+        {
+            "type": "NOT_FOUND",
+            "path": ["organization"],
+            "message": "Could not resolve to an Organization with the login of 'typo'."
+        }
+    ]
+}`
+
+func TestGetOrganizationIPAllowListEntryByIDOfMissingOrganization(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := serverReturning(getOrganizationNotFoundResponse)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	_, err := client.GetOrganizationIPAllowListEntryByID(context.TODO(), "typo", someCachedEntry.ID)
+
+	// then
+	var listingErr ListingError
+	assert.ErrorAs(t, err, &listingErr)
+	assert.Equal(t, OrganizationOwner("typo"), listingErr.Owner)
+	assert.ErrorIs(t, listingErr.Err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
+
+func TestGetOrganizationIPAllowListEntriesByCIDR(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := serverReturning(getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	entries, err := client.GetOrganizationIPAllowListEntriesByCIDR(context.TODO(), "some organization", "1.2.3.4")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCachedEntry}, entries)
+}
+
+func TestGetEnterpriseIPAllowListEntryByIDFindsCreatedEntryWithoutListingAgain(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getEnterpriseIPAllowListEntriesResponseLastPageWith(someCachedEntry),
+		createEntryResponseWithOwner(someCreatedEntry, `{"slug": "some enterprise"}`),
+	)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	_, _ = client.GetEnterpriseIPAllowListEntries(context.TODO(), "some enterprise")
+	_, _ = client.CreateIPAllowListEntry(context.TODO(), "some owner", someCreatedEntry.Name, someCreatedEntry.AllowListValue, someCreatedEntry.IsActive)
+
+	// when
+	entry, err := client.GetEnterpriseIPAllowListEntryByID(context.TODO(), "some enterprise", someCreatedEntry.ID)
+	entries, cidrErr := client.GetEnterpriseIPAllowListEntriesByCIDR(context.TODO(), "some enterprise", someCreatedEntry.AllowListValue)
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, cidrErr)
+	assert.Equal(t, &someCreatedEntry, entry)
+	assert.Equal(t, []*IPAllowListEntry{&someCreatedEntry}, entries)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
@@ -0,0 +1,159 @@
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Kinds of errors returned by GitHub's API. Errors returned by the client match them with errors.Is, e.g. errors.Is(err, github.ErrNotFound).
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrValidation   = errors.New("validation failed")
+)
+
+// graphQLErrorKinds maps types of GraphQL errors to their kinds.
+var graphQLErrorKinds = map[string]error{
+	"NOT_FOUND":      ErrNotFound,
+	"FORBIDDEN":      ErrForbidden,
+	"UNAUTHORIZED":   ErrUnauthorized,
+	"RATE_LIMITED":   ErrRateLimited,
+	"UNPROCESSABLE":  ErrValidation,
+	"ARGUMENT_ERROR": ErrValidation,
+}
+
+// ErrorWithStatusCode is an error returned with an unsuccessful HTTP response.
+type ErrorWithStatusCode struct {
+	StatusCode int
+	// RequestID is a value of the X-GitHub-Request-Id header of the response, if any.
+	RequestID string
+	message   string
+	kind      error
+}
+
+func newErrorWithStatusCode(res *http.Response, body []byte, message string) ErrorWithStatusCode {
+	return ErrorWithStatusCode{
+		StatusCode: res.StatusCode,
+		RequestID:  res.Header.Get("X-GitHub-Request-Id"),
+		message:    message,
+		kind:       statusCodeErrorKind(res, body),
+	}
+}
+
+func (e ErrorWithStatusCode) Error() string {
+	return e.message
+}
+
+// Is reports whether the error is of a given kind, e.g. ErrNotFound.
+func (e ErrorWithStatusCode) Is(target error) bool {
+	return e.kind != nil && e.kind == target
+}
+
+func statusCodeErrorKind(res *http.Response, body []byte) error {
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		if res.Header.Get("X-RateLimit-Remaining") == "0" || bytes.Contains(bytes.ToLower(body), []byte("rate limit")) {
+			return ErrRateLimited
+		}
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnprocessableEntity:
+		return ErrValidation
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		return nil
+	}
+}
+
+// ListingError is an error listing entries of an owner while looking up one of them, e.g. for an owner which does not exist or is not visible to the token.
+// It never matches a kind with errors.Is, so that it is not mistaken for a missing entry, which lookups report with ErrNotFound.
+// The underlying error is available as Err.
+type ListingError struct {
+	Owner Owner
+	Err   error
+}
+
+func (e ListingError) Error() string {
+	return fmt.Sprintf("listing entries of %s: %s", e.Owner, e.Err)
+}
+
+// Location is a position in a GraphQL document an error refers to.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is an error returned by GitHub's GraphQL API. Its kind is determined by Type, e.g. NOT_FOUND matches ErrNotFound.
+// Errors without Type but with a code in Extensions are query validation errors matching ErrValidation.
+type Error struct {
+	Type       string         `json:"type"`
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations"`
+	Path       []any          `json:"path"`
+	Extensions map[string]any `json:"extensions"`
+	// RequestID is a value of the X-GitHub-Request-Id header of the response, if any.
+	RequestID string `json:"-"`
+}
+
+func (e Error) Error() string {
+	if e.Type == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+}
+
+// Is reports whether the error is of a given kind, e.g. ErrNotFound.
+func (e Error) Is(target error) bool {
+	kind, ok := graphQLErrorKinds[e.Type]
+	if !ok && e.Type == "" && e.Extensions["code"] != nil {
+		kind = ErrValidation
+	}
+	return kind != nil && kind == target
+}
+
+// GraphQLErrors are errors returned by GitHub's GraphQL API with a single response.
+// It matches a kind with errors.Is and an Error with errors.As if any of the errors does.
+type GraphQLErrors struct {
+	Errors []Error
+}
+
+func (e GraphQLErrors) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%d GraphQL errors: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Is reports whether any of the errors is of a given kind.
+func (e GraphQLErrors) Is(target error) bool {
+	for _, err := range e.Errors {
+		if err.Is(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As sets target to the first of the errors if target is a pointer to an Error.
+func (e GraphQLErrors) As(target any) bool {
+	t, ok := target.(*Error)
+	if !ok || len(e.Errors) == 0 {
+		return false
+	}
+	*t = e.Errors[0]
+	return true
+}
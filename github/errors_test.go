@@ -0,0 +1,123 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const responseWithMultipleGraphQLErrors = `{
+    "data": null,
+    "errors": [
+        {
+            "type": "FORBIDDEN",
+            "path": ["organization", "ipAllowListEntries", "nodes", 0],
+            "locations": [{"line": 3, "column": 5}],
+            "message": "Resource not accessible by integration"
+        },
+        {
+            "path": ["query", "organization", "foo"],
+            "extensions": {"code": "undefinedField", "typeName": "Organization", "fieldName": "foo"},
+            "locations": [{"line": 4, "column": 5}],
+            "message": "Field 'foo' doesn't exist on type 'Organization'"
+        }
+    ]
+}`
+
+func TestClientReturnsTypedGraphQLErrors(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-GitHub-Request-Id", "some-request-id")
+		_, _ = w.Write([]byte(responseWithMultipleGraphQLErrors))
+	}))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	_, err := client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.NotErrorIs(t, err, ErrNotFound)
+
+	var gqlErrs GraphQLErrors
+	assert.ErrorAs(t, err, &gqlErrs)
+	assert.Len(t, gqlErrs.Errors, 2)
+
+	var gqlErr Error
+	assert.ErrorAs(t, err, &gqlErr)
+	assert.Equal(t, Error{
+		Type:      "FORBIDDEN",
+		Message:   "Resource not accessible by integration",
+		Locations: []Location{{Line: 3, Column: 5}},
+		Path:      []any{"organization", "ipAllowListEntries", "nodes", float64(0)},
+		RequestID: "some-request-id",
+	}, gqlErr)
+}
+
+func TestClientReturnsNotFoundGraphQLError(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := serverReturningGrapeQLError("Could not resolve to a node with the global id of 'abc'.")
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	_, err := client.DeleteIPAllowListEntry(context.TODO(), "abc")
+
+	// then
+	assert.ErrorIs(t, err, ErrNotFound)
+	var gqlErr Error
+	assert.ErrorAs(t, err, &gqlErr)
+	assert.EqualError(t, gqlErr, "NOT_FOUND: Could not resolve to a node with the global id of 'abc'.")
+}
+
+func TestClientReturnsTypedErrorsWithStatusCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		headers      map[string]string
+		body         string
+		expectedKind error
+	}{
+		{name: "unauthorized", statusCode: http.StatusUnauthorized, body: `{"message": "Bad credentials"}`, expectedKind: ErrUnauthorized},
+		{name: "forbidden", statusCode: http.StatusForbidden, body: `{"message": "Must have admin rights"}`, expectedKind: ErrForbidden},
+		{name: "primary rate limit", statusCode: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0"}, expectedKind: ErrRateLimited},
+		{name: "secondary rate limit", statusCode: http.StatusForbidden, body: secondaryRateLimitResponse, expectedKind: ErrRateLimited},
+		{name: "too many requests", statusCode: http.StatusTooManyRequests, expectedKind: ErrRateLimited},
+		{name: "not found", statusCode: http.StatusNotFound, expectedKind: ErrNotFound},
+		{name: "unprocessable entity", statusCode: http.StatusUnprocessableEntity, expectedKind: ErrValidation},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			headers := map[string]string{"X-GitHub-Request-Id": "some-request-id"}
+			for k, v := range test.headers {
+				headers[k] = v
+			}
+			gitHubGraphQLAPIMock, _ := serverFailingBeforeResponding(1, test.statusCode, headers, test.body, someGetOrganizationIDResponse)
+			client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+			// when
+			_, err := client.GetOrganizationID(context.TODO(), "some organization")
+
+			// then
+			assert.ErrorIs(t, err, test.expectedKind)
+			var target ErrorWithStatusCode
+			assert.ErrorAs(t, err, &target)
+			assert.Equal(t, test.statusCode, target.StatusCode)
+			assert.Equal(t, "some-request-id", target.RequestID)
+		})
+	}
+}
+
+func TestErrorWithStatusCodeWithoutKind(t *testing.T) {
+	// given
+	err := newErrorWithStatusCode(&http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}}, nil, "some error")
+
+	// then
+	for _, kind := range []error{ErrNotFound, ErrForbidden, ErrUnauthorized, ErrRateLimited, ErrValidation} {
+		assert.NotErrorIs(t, err, kind)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 )
 
+// CIDR is a single IP address or a range of IP addresses in CIDR notation.
 type CIDR string
 
 type IPAllowListEntryParameters struct {
@@ -14,6 +15,22 @@ type IPAllowListEntryParameters struct {
 	IsActive bool
 }
 
+// IPAllowListEnabledSettingValue is a value of an owner's IP allow list enabled setting.
+type IPAllowListEnabledSettingValue string
+
+const (
+	IPAllowListEnabledSettingEnabled  IPAllowListEnabledSettingValue = "ENABLED"
+	IPAllowListEnabledSettingDisabled IPAllowListEnabledSettingValue = "DISABLED"
+)
+
+// IPAllowListForInstalledAppsEnabledSettingValue is a value of an owner's setting deciding whether IP allow list configuration of installed GitHub Apps is inherited.
+type IPAllowListForInstalledAppsEnabledSettingValue string
+
+const (
+	IPAllowListForInstalledAppsEnabledSettingEnabled  IPAllowListForInstalledAppsEnabledSettingValue = "ENABLED"
+	IPAllowListForInstalledAppsEnabledSettingDisabled IPAllowListForInstalledAppsEnabledSettingValue = "DISABLED"
+)
+
 type IPAllowListEntry struct {
 	ID             string    `json:"id"`
 	AllowListValue CIDR      `json:"allowListValue"`
@@ -35,6 +52,18 @@ mutation CreateIpAllowListEntry($ownerId: ID!, $name: String = "", $value: Strin
       isActive
       createdAt
       updatedAt
+      owner {
+        __typename
+        ... on Organization {
+          login
+        }
+        ... on Enterprise {
+          slug
+        }
+        ... on App {
+          id
+        }
+      }
     }
   }
 }`
@@ -59,7 +88,10 @@ type DeleteUpAllowListEntryMutationResponse struct {
 
 type CreateIPAllowListEntryMutationResponse struct {
 	CreateIPAllowListEntry struct {
-		IPAllowListEntry IPAllowListEntry `json:"ipAllowListEntry"`
+		IPAllowListEntry struct {
+			IPAllowListEntry
+			Owner *ipAllowListEntryOwner `json:"owner"`
+		} `json:"ipAllowListEntry"`
 	} `json:"createIpAllowListEntry"`
 }
 
@@ -85,8 +117,62 @@ type UpdateIPAllowListEntryMutationResponse struct {
 	} `json:"updateIpAllowListEntry"`
 }
 
-// CreateIPAllowListEntry uses createIpAllowListEntry GraphQL mutation to create a new IP allow list entry for a given ownerID (organization or enterprise).
-// Returns the newly created entry.
+const updateIPAllowListEnabledSettingMutation = `
+mutation UpdateIpAllowListEnabledSetting($ownerId: ID!, $settingValue: IpAllowListEnabledSettingValue!) {
+  updateIpAllowListEnabledSetting(input: {ownerId: $ownerId, settingValue: $settingValue}) {
+    owner {
+      ... on Organization {
+        ipAllowListEnabledSetting
+      }
+      ... on Enterprise {
+        ownerInfo {
+          ipAllowListEnabledSetting
+        }
+      }
+    }
+  }
+}`
+
+type UpdateIPAllowListEnabledSettingMutationResponse struct {
+	UpdateIPAllowListEnabledSetting struct {
+		Owner struct {
+			IPAllowListEnabledSetting IPAllowListEnabledSettingValue `json:"ipAllowListEnabledSetting"`
+			OwnerInfo                 struct {
+				IPAllowListEnabledSetting IPAllowListEnabledSettingValue `json:"ipAllowListEnabledSetting"`
+			} `json:"ownerInfo"`
+		} `json:"owner"`
+	} `json:"updateIpAllowListEnabledSetting"`
+}
+
+const updateIPAllowListForInstalledAppsEnabledSettingMutation = `
+mutation UpdateIpAllowListForInstalledAppsEnabledSetting($ownerId: ID!, $settingValue: IpAllowListForInstalledAppsEnabledSettingValue!) {
+  updateIpAllowListForInstalledAppsEnabledSetting(input: {ownerId: $ownerId, settingValue: $settingValue}) {
+    owner {
+      ... on Organization {
+        ipAllowListForInstalledAppsEnabledSetting
+      }
+      ... on Enterprise {
+        ownerInfo {
+          ipAllowListForInstalledAppsEnabledSetting
+        }
+      }
+    }
+  }
+}`
+
+type UpdateIPAllowListForInstalledAppsEnabledSettingMutationResponse struct {
+	UpdateIPAllowListForInstalledAppsEnabledSetting struct {
+		Owner struct {
+			IPAllowListForInstalledAppsEnabledSetting IPAllowListForInstalledAppsEnabledSettingValue `json:"ipAllowListForInstalledAppsEnabledSetting"`
+			OwnerInfo                                 struct {
+				IPAllowListForInstalledAppsEnabledSetting IPAllowListForInstalledAppsEnabledSettingValue `json:"ipAllowListForInstalledAppsEnabledSetting"`
+			} `json:"ownerInfo"`
+		} `json:"owner"`
+	} `json:"updateIpAllowListForInstalledAppsEnabledSetting"`
+}
+
+// CreateIPAllowListEntry uses createIpAllowListEntry GraphQL mutation to create a new IP allow list entry for a given ownerID (organization, enterprise or app).
+// Returns the newly created entry, which is also added to cached entries of its owner.
 func (c *Client) CreateIPAllowListEntry(ctx context.Context, ownerID string, name string, value CIDR, isActive bool) (*IPAllowListEntry, error) {
 	reqData := GraphQLRequest{
 		Query: createIPAllowListEntryMutation,
@@ -105,11 +191,15 @@ func (c *Client) CreateIPAllowListEntry(ctx context.Context, ownerID string, nam
 		return nil, errors.Wrap(err, "CreateIPAllowListEntry error")
 	}
 
-	return &resData.CreateIPAllowListEntry.IPAllowListEntry, nil
+	created := resData.CreateIPAllowListEntry.IPAllowListEntry
+	c.cacheCreatedEntry(ctx, created.Owner, created.IPAllowListEntry)
+
+	return &created.IPAllowListEntry, nil
 }
 
 // DeleteIPAllowListEntry uses deleteIpAllowListEntry GraphQL mutation to delete an IP allow list entry with a given entryID.
-// Returns entryID of the deleted entry.
+// Returns entryID of the deleted entry, which is also removed from cached entries.
+// An entry which does not exist is removed from cached entries too, and an error matching ErrNotFound is returned.
 func (c *Client) DeleteIPAllowListEntry(ctx context.Context, entryID string) (string, error) {
 	reqData := GraphQLRequest{
 		Query: deleteIPAllowListEntryMutation,
@@ -119,14 +209,20 @@ func (c *Client) DeleteIPAllowListEntry(ctx context.Context, entryID string) (st
 
 	resData, err := doRequest[DeleteUpAllowListEntryMutationResponse](ctx, c, reqData)
 	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			c.cacheDeletedEntry(ctx, entryID)
+		}
 		return "", errors.Wrap(err, "DeleteIPAllowListEntry error")
 	}
 
+	c.cacheDeletedEntry(ctx, entryID)
+
 	return resData.DeleteIPAllowListEntry.IPAllowListEntry.ID, nil
 }
 
 // UpdateIPAllowListEntry uses updateIpAllowListEntry GraphQL mutation to set attributes an IP allow list entry with a given entryID to params.
-// Returns the updated entry.
+// Returns the updated entry, which also replaces the cached one.
+// An entry which does not exist is removed from cached entries, and an error matching ErrNotFound is returned.
 func (c *Client) UpdateIPAllowListEntry(ctx context.Context, entryID string, params IPAllowListEntryParameters) (*IPAllowListEntry, error) {
 	reqData := GraphQLRequest{
 		Query: updateIPAllowListEntryMutation,
@@ -139,8 +235,186 @@ func (c *Client) UpdateIPAllowListEntry(ctx context.Context, entryID string, par
 
 	resData, err := doRequest[UpdateIPAllowListEntryMutationResponse](ctx, c, reqData)
 	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			c.cacheDeletedEntry(ctx, entryID)
+		}
 		return nil, errors.Wrap(err, "UpdateIPAllowListEntry error")
 	}
 
+	c.cacheUpdatedEntry(ctx, resData.UpdateIPAllowListEntry.IPAllowListEntry)
+
 	return &resData.UpdateIPAllowListEntry.IPAllowListEntry, nil
 }
+
+// UpdateIPAllowListEnabledSetting uses updateIpAllowListEnabledSetting GraphQL mutation to enable or disable IP allow list of a given ownerID (organization, enterprise or app).
+// Returns the updated setting value.
+func (c *Client) UpdateIPAllowListEnabledSetting(ctx context.Context, ownerID string, value IPAllowListEnabledSettingValue) (IPAllowListEnabledSettingValue, error) {
+	reqData := GraphQLRequest{
+		Query: updateIPAllowListEnabledSettingMutation,
+		Variables: map[string]any{
+			"ownerId":      ownerID,
+			"settingValue": value,
+		}}
+
+	resData, err := doRequest[UpdateIPAllowListEnabledSettingMutationResponse](ctx, c, reqData)
+	if err != nil {
+		return "", errors.Wrap(err, "UpdateIPAllowListEnabledSetting error")
+	}
+
+	owner := resData.UpdateIPAllowListEnabledSetting.Owner
+	if owner.IPAllowListEnabledSetting != "" {
+		return owner.IPAllowListEnabledSetting, nil
+	}
+	return owner.OwnerInfo.IPAllowListEnabledSetting, nil
+}
+
+// UpdateIPAllowListForInstalledAppsEnabledSetting uses updateIpAllowListForInstalledAppsEnabledSetting GraphQL mutation to decide
+// whether IP allow list configuration of GitHub Apps installed on a given ownerID (organization or enterprise) is inherited.
+// Returns the updated setting value.
+func (c *Client) UpdateIPAllowListForInstalledAppsEnabledSetting(ctx context.Context, ownerID string, value IPAllowListForInstalledAppsEnabledSettingValue) (IPAllowListForInstalledAppsEnabledSettingValue, error) {
+	reqData := GraphQLRequest{
+		Query: updateIPAllowListForInstalledAppsEnabledSettingMutation,
+		Variables: map[string]any{
+			"ownerId":      ownerID,
+			"settingValue": value,
+		}}
+
+	resData, err := doRequest[UpdateIPAllowListForInstalledAppsEnabledSettingMutationResponse](ctx, c, reqData)
+	if err != nil {
+		return "", errors.Wrap(err, "UpdateIPAllowListForInstalledAppsEnabledSetting error")
+	}
+
+	owner := resData.UpdateIPAllowListForInstalledAppsEnabledSetting.Owner
+	if owner.IPAllowListForInstalledAppsEnabledSetting != "" {
+		return owner.IPAllowListForInstalledAppsEnabledSetting, nil
+	}
+	return owner.OwnerInfo.IPAllowListForInstalledAppsEnabledSetting, nil
+}
+
+// maxNodesPerQuery is the maximum number of IDs GitHub accepts in a single nodes query.
+const maxNodesPerQuery = 100
+
+const getIPAllowListEntryQuery = `
+query GetIpAllowListEntry($entryId: ID!) {
+  node(id: $entryId) {
+    ... on IpAllowListEntry {
+      id
+      allowListValue
+      name
+      isActive
+      createdAt
+      updatedAt
+    }
+  }
+}`
+
+type GetIPAllowListEntryQueryResponse struct {
+	Node *IPAllowListEntry `json:"node"`
+}
+
+const getIPAllowListEntriesQuery = `
+query GetIpAllowListEntries($entryIds: [ID!]!) {
+  nodes(ids: $entryIds) {
+    ... on IpAllowListEntry {
+      id
+      allowListValue
+      name
+      isActive
+      createdAt
+      updatedAt
+    }
+  }
+}`
+
+type GetIPAllowListEntriesQueryResponse struct {
+	Nodes []*IPAllowListEntry `json:"nodes"`
+}
+
+// GetIPAllowListEntry fetches a single IP allow list entry with a given entryID, without listing entries of its owner.
+// Returns an error matching ErrNotFound if there is no such entry. Cached entries are neither used nor updated.
+func (c *Client) GetIPAllowListEntry(ctx context.Context, entryID string) (*IPAllowListEntry, error) {
+	reqData := GraphQLRequest{
+		Query: getIPAllowListEntryQuery,
+		Variables: map[string]any{
+			"entryId": entryID,
+		}}
+
+	resData, err := doRequest[GetIPAllowListEntryQueryResponse](ctx, c, reqData)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetIPAllowListEntry error")
+	}
+	if resData.Node == nil || resData.Node.ID == "" {
+		return nil, errors.Wrapf(ErrNotFound, "GetIPAllowListEntry error: no IP allow list entry with ID %s", entryID)
+	}
+
+	return resData.Node, nil
+}
+
+// GetIPAllowListEntries fetches IP allow list entries with given entryIDs, without listing entries of their owners.
+// IDs are sent in batches of up to 100. Returns entries in the order of entryIDs, with nil for IDs of missing entries.
+// Cached entries are neither used nor updated.
+func (c *Client) GetIPAllowListEntries(ctx context.Context, entryIDs []string) ([]*IPAllowListEntry, error) {
+	entries := make([]*IPAllowListEntry, 0, len(entryIDs))
+	for start := 0; start < len(entryIDs); start += maxNodesPerQuery {
+		end := start + maxNodesPerQuery
+		if end > len(entryIDs) {
+			end = len(entryIDs)
+		}
+		batch, err := c.getIPAllowListEntries(ctx, entryIDs[start:end])
+		if err != nil {
+			return nil, errors.Wrap(err, "GetIPAllowListEntries error")
+		}
+		entries = append(entries, batch...)
+	}
+	return entries, nil
+}
+
+func (c *Client) getIPAllowListEntries(ctx context.Context, entryIDs []string) ([]*IPAllowListEntry, error) {
+	reqData := GraphQLRequest{
+		Query: getIPAllowListEntriesQuery,
+		Variables: map[string]any{
+			"entryIds": entryIDs,
+		}}
+
+	gqlRes, err := c.doGraphQLRequest(ctx, reqData)
+	if err != nil {
+		return nil, err
+	}
+	// Missing entries are reported as NOT_FOUND errors next to null nodes.
+	var errs []Error
+	for _, e := range gqlRes.Errors {
+		if !e.Is(ErrNotFound) {
+			errs = append(errs, e)
+		}
+	}
+	gqlRes.Errors = errs
+	err = handleErrors(gqlRes)
+	if err != nil {
+		return nil, err
+	}
+
+	resData, err := toResponseData[GetIPAllowListEntriesQueryResponse](gqlRes)
+	if err != nil {
+		return nil, err
+	}
+	if len(resData.Nodes) != len(entryIDs) {
+		return nil, errors.Errorf("unexpected number of nodes, got %d for %d IDs", len(resData.Nodes), len(entryIDs))
+	}
+	for i, e := range resData.Nodes {
+		if e != nil && e.ID == "" {
+			resData.Nodes[i] = nil
+		}
+	}
+	return resData.Nodes, nil
+}
+
+// forEachEntry calls fn with each entry until it returns an error.
+func forEachEntry(entries []*IPAllowListEntry, fn func(*IPAllowListEntry) error) error {
+	for _, e := range entries {
+		err := fn(e)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
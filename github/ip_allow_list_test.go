@@ -2,9 +2,11 @@ package github
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -102,6 +104,54 @@ const updateEntryResponseForMissingEntry = `
     ]
 }`
 
+const updateOrganizationIPAllowListEnabledSettingResponseTemplate = `
+{
+    "data": {
+        "updateIpAllowListEnabledSetting": {
+            "owner": {
+                "ipAllowListEnabledSetting": "%s"
+            }
+        }
+    }
+}`
+
+const updateEnterpriseIPAllowListEnabledSettingResponseTemplate = `
+{
+    "data": {
+        "updateIpAllowListEnabledSetting": {
+            "owner": {
+                "ownerInfo": {
+                    "ipAllowListEnabledSetting": "%s"
+                }
+            }
+        }
+    }
+}`
+
+const updateOrganizationIPAllowListForInstalledAppsEnabledSettingResponseTemplate = `
+{
+    "data": {
+        "updateIpAllowListForInstalledAppsEnabledSetting": {
+            "owner": {
+                "ipAllowListForInstalledAppsEnabledSetting": "%s"
+            }
+        }
+    }
+}`
+
+const updateEnterpriseIPAllowListForInstalledAppsEnabledSettingResponseTemplate = `
+{
+    "data": {
+        "updateIpAllowListForInstalledAppsEnabledSetting": {
+            "owner": {
+                "ownerInfo": {
+                    "ipAllowListForInstalledAppsEnabledSetting": "%s"
+                }
+            }
+        }
+    }
+}`
+
 func TestCreateIPAllowListEntry(t *testing.T) {
 	// given
 	expectedEntry := IPAllowListEntry{
@@ -162,7 +212,7 @@ func TestDeleteIPAllowListEntryWithMissingEntry(t *testing.T) {
 	deletedEntryID, err := client.DeleteIPAllowListEntry(context.TODO(), "some-entry-id")
 
 	// then
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrNotFound)
 	assert.Contains(t, err.Error(), "Could not resolve to a node with the global id of 'abc-123'.")
 	assert.Empty(t, deletedEntryID)
 }
@@ -218,7 +268,7 @@ func TestUpdateIPAllowListEntryWithMissingEntry(t *testing.T) {
 	deletedEntryID, err := client.UpdateIPAllowListEntry(context.TODO(), "some-entry-id", someIPAllowListEntryParameters)
 
 	// then
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrNotFound)
 	assert.Contains(t, err.Error(), "Could not resolve to a node with the global id of 'abc-123'")
 	assert.Empty(t, deletedEntryID)
 }
@@ -239,6 +289,76 @@ func TestUpdateIPAllowListEntryWithFailingServer(t *testing.T) {
 	assert.Empty(t, deletedEntryID)
 }
 
+func TestUpdateIPAllowListEnabledSetting(t *testing.T) {
+	tests := []struct {
+		owner            string
+		responseTemplate string
+		expectedValue    IPAllowListEnabledSettingValue
+	}{
+		{owner: "organization", responseTemplate: updateOrganizationIPAllowListEnabledSettingResponseTemplate, expectedValue: IPAllowListEnabledSettingEnabled},
+		{owner: "organization", responseTemplate: updateOrganizationIPAllowListEnabledSettingResponseTemplate, expectedValue: IPAllowListEnabledSettingDisabled},
+		{owner: "enterprise", responseTemplate: updateEnterpriseIPAllowListEnabledSettingResponseTemplate, expectedValue: IPAllowListEnabledSettingEnabled},
+		{owner: "enterprise", responseTemplate: updateEnterpriseIPAllowListEnabledSettingResponseTemplate, expectedValue: IPAllowListEnabledSettingDisabled},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s:%s", test.owner, test.expectedValue), func(t *testing.T) {
+			// given
+			gitHubGraphQLAPIMock := serverReturning(fmt.Sprintf(test.responseTemplate, test.expectedValue))
+			client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+			// when
+			value, err := client.UpdateIPAllowListEnabledSetting(context.TODO(), "some owner", test.expectedValue)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
+
+func TestUpdateIPAllowListEnabledSettingWithFailingServer(t *testing.T) {
+	// given
+	expectedStatusCode := http.StatusInternalServerError
+	gitHubGraphQLAPIMock := serverReturningAnEmptyResponseWith(expectedStatusCode)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	value, err := client.UpdateIPAllowListEnabledSetting(context.TODO(), "some owner", IPAllowListEnabledSettingEnabled)
+
+	// then
+	var target ErrorWithStatusCode
+	assert.ErrorAs(t, err, &target)
+	assert.Equal(t, target.StatusCode, expectedStatusCode)
+	assert.Empty(t, value)
+}
+
+func TestUpdateIPAllowListForInstalledAppsEnabledSetting(t *testing.T) {
+	tests := []struct {
+		owner            string
+		responseTemplate string
+		expectedValue    IPAllowListForInstalledAppsEnabledSettingValue
+	}{
+		{owner: "organization", responseTemplate: updateOrganizationIPAllowListForInstalledAppsEnabledSettingResponseTemplate, expectedValue: IPAllowListForInstalledAppsEnabledSettingEnabled},
+		{owner: "organization", responseTemplate: updateOrganizationIPAllowListForInstalledAppsEnabledSettingResponseTemplate, expectedValue: IPAllowListForInstalledAppsEnabledSettingDisabled},
+		{owner: "enterprise", responseTemplate: updateEnterpriseIPAllowListForInstalledAppsEnabledSettingResponseTemplate, expectedValue: IPAllowListForInstalledAppsEnabledSettingEnabled},
+		{owner: "enterprise", responseTemplate: updateEnterpriseIPAllowListForInstalledAppsEnabledSettingResponseTemplate, expectedValue: IPAllowListForInstalledAppsEnabledSettingDisabled},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s:%s", test.owner, test.expectedValue), func(t *testing.T) {
+			// given
+			gitHubGraphQLAPIMock := serverReturning(fmt.Sprintf(test.responseTemplate, test.expectedValue))
+			client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+			// when
+			value, err := client.UpdateIPAllowListForInstalledAppsEnabledSetting(context.TODO(), "some owner", test.expectedValue)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
+
 func createEntryResponseWith(expectedEntry IPAllowListEntry) string {
 	res := fmt.Sprintf(createEntryResponseTemplate, expectedEntry.ID, expectedEntry.CreatedAt.Format(gitHubTimeFormat), expectedEntry.UpdatedAt.Format(gitHubTimeFormat), expectedEntry.AllowListValue, expectedEntry.IsActive, expectedEntry.Name)
 	return res
@@ -252,3 +372,120 @@ func updateEntryResponseWith(expectedEntry IPAllowListEntry) string {
 	res := fmt.Sprintf(updateEntryResponseTemplate, expectedEntry.ID, expectedEntry.AllowListValue, expectedEntry.IsActive, expectedEntry.Name, expectedEntry.CreatedAt.Format(gitHubTimeFormat), expectedEntry.UpdatedAt.Format(gitHubTimeFormat))
 	return res
 }
+
+func TestGetIPAllowListEntry(t *testing.T) {
+	// given
+	expectedEntry := IPAllowListEntry{
+		ID:             "some-entry-id",
+		CreatedAt:      truncateToGitHubPrecision(time.Now()),
+		UpdatedAt:      truncateToGitHubPrecision(time.Now()),
+		AllowListValue: "1.2.3.4/32",
+		IsActive:       true,
+		Name:           "some name",
+	}
+	gitHubGraphQLAPIMock := serverReturning(fmt.Sprintf(`{"data": {"node": %s}}`, entryJSON(expectedEntry)))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	entry, err := client.GetIPAllowListEntry(context.TODO(), expectedEntry.ID)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEntry, *entry)
+}
+
+func TestGetIPAllowListEntryWithMissingEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{name: "missing node", response: `{"data": {"node": null}, "errors": [{"type": "NOT_FOUND", "path": ["node"], "message": "Could not resolve to a node with the global id of 'abc-123'."}]}`},
+		{name: "node of another type", response: `{"data": {"node": {}}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			gitHubGraphQLAPIMock := serverReturning(test.response)
+			client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+			// when
+			entry, err := client.GetIPAllowListEntry(context.TODO(), "abc-123")
+
+			// then
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.Nil(t, entry)
+		})
+	}
+}
+
+func TestGetIPAllowListEntries(t *testing.T) {
+	// given
+	someEntry := IPAllowListEntry{
+		ID:             "some-entry-id",
+		CreatedAt:      truncateToGitHubPrecision(time.Now()),
+		UpdatedAt:      truncateToGitHubPrecision(time.Now()),
+		AllowListValue: "1.2.3.4/32",
+		IsActive:       true,
+		Name:           "some name",
+	}
+	gitHubGraphQLAPIMock := serverReturning(fmt.Sprintf(`{
+    "data": {"nodes": [%s, null, {}]},
+    "errors": [{"type": "NOT_FOUND", "path": ["nodes", 1], "message": "Could not resolve to a node with the global id of 'missing-id'."}]
+}`, entryJSON(someEntry)))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	entries, err := client.GetIPAllowListEntries(context.TODO(), []string{someEntry.ID, "missing-id", "other-node-id"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someEntry, nil, nil}, entries)
+}
+
+func TestGetIPAllowListEntriesInBatches(t *testing.T) {
+	// given
+	var batchSizes []int
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		ids := req.Variables["entryIds"].([]any)
+		batchSizes = append(batchSizes, len(ids))
+		nodes := make([]map[string]any, len(ids))
+		for i, id := range ids {
+			nodes[i] = map[string]any{"id": id}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"nodes": nodes}})
+	}))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	ids := make([]string, 250)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("id-%d", i)
+	}
+
+	// when
+	entries, err := client.GetIPAllowListEntries(context.TODO(), ids)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []int{100, 100, 50}, batchSizes)
+	assert.Len(t, entries, 250)
+	assert.Equal(t, "id-249", entries[249].ID)
+}
+
+func TestGetIPAllowListEntriesWithFailingServer(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := serverReturning(`{"data": null, "errors": [{"type": "FORBIDDEN", "path": ["nodes"], "message": "Resource not accessible by integration"}]}`)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	entries, err := client.GetIPAllowListEntries(context.TODO(), []string{"some-id"})
+
+	// then
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.Nil(t, entries)
+}
+
+func entryJSON(entry IPAllowListEntry) string {
+	return fmt.Sprintf(`{"id": "%s", "createdAt": "%s", "updatedAt": "%s", "allowListValue": "%s", "isActive": %t, "name": "%s"}`,
+		entry.ID, entry.CreatedAt.Format(gitHubTimeFormat), entry.UpdatedAt.Format(gitHubTimeFormat), entry.AllowListValue, entry.IsActive, entry.Name)
+}
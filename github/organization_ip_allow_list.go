@@ -5,109 +5,92 @@ import (
 	"github.com/pkg/errors"
 )
 
-const getOrganizationIDQuery = `
-query GetOrganizationId($organizationName: String!) {
-  organization(login: $organizationName) {
-    id
-  }
-}`
+// GetOrganizationIPAllowListEntries retrieves IP allow list entries for a given organizationName.
+// Returns a slice of pointers to an entry as the API returns nil for entries managed on an enterprise level.
+// Use GetOrganizationInheritedIPAllowListEntries to count or resolve them.
+func (c *Client) GetOrganizationIPAllowListEntries(ctx context.Context, organizationName string) ([]*IPAllowListEntry, error) {
+	return c.GetOwnerIPAllowListEntries(ctx, OrganizationOwner(organizationName))
+}
 
-type GetOrganizationIDQueryResponse struct {
-	Organization struct {
-		ID string `json:"id"`
-	} `json:"organization"`
+// GetOrganizationIPAllowListEntryByID returns an entry with a given entryID among IP allow list entries of a given organizationName.
+// Entries are listed like with GetOrganizationIPAllowListEntries, and looked up without scanning them when they are cached.
+// Returns an error matching ErrNotFound if there is no such entry, and a ListingError if entries cannot be listed.
+func (c *Client) GetOrganizationIPAllowListEntryByID(ctx context.Context, organizationName string, entryID string) (*IPAllowListEntry, error) {
+	return c.GetOwnerIPAllowListEntryByID(ctx, OrganizationOwner(organizationName), entryID)
 }
 
-const getOrganizationIPAllowListEntriesQuery = `
-query GetOrganizationIpAllowListEntries($org: String!, $after: String) {
-  organization(login: $org) {
-    ipAllowListEntries(first: 100, after: $after) {
-      nodes {
-        id
-        allowListValue
-        name
-        isActive
-        createdAt
-        updatedAt
-      }
-      pageInfo {
-        hasNextPage
-        startCursor
-        endCursor
-      }
-    }
-  }
-}`
+// GetOrganizationIPAllowListEntriesByCIDR returns IP allow list entries of a given organizationName with a value equal to a given value regardless of its notation,
+// e.g. 1.2.3.4 and 1.2.3.4/32. Entries are listed like with GetOrganizationIPAllowListEntries, and looked up without scanning them when they are cached.
+func (c *Client) GetOrganizationIPAllowListEntriesByCIDR(ctx context.Context, organizationName string, value CIDR) ([]*IPAllowListEntry, error) {
+	return c.GetOwnerIPAllowListEntriesByCIDR(ctx, OrganizationOwner(organizationName), value)
+}
 
-type GetOrganizationIPAllowListQueryResponse struct {
-	Organization struct {
-		IPAllowListEntries struct {
-			Nodes    []*IPAllowListEntry `json:"nodes"`
-			PageInfo PageInfo            `json:"pageInfo"`
-		} `json:"ipAllowListEntries"`
-	} `json:"organization"`
+// ForEachOrganizationIPAllowListEntry calls fn with each IP allow list entry of a given organizationName, in the order GitHub lists them.
+// Entries are requested page by page as fn consumes them, so memory stays flat regardless of the number of entries, unless fresh entries are cached,
+// in which case fn is called with cached entries. Streamed entries are not cached.
+// fn is called with nil for entries managed on an enterprise level, like GetOrganizationIPAllowListEntries returns them.
+// Iteration stops at the first error returned by fn, which is returned unless it is ErrStopIteration.
+func (c *Client) ForEachOrganizationIPAllowListEntry(ctx context.Context, organizationName string, fn func(*IPAllowListEntry) error) error {
+	return c.ForEachOwnerIPAllowListEntry(ctx, OrganizationOwner(organizationName), fn)
 }
 
-// GetOrganizationIPAllowListEntries retrieves IP allow list entries for a given organizationName.
-// Returns a slice of pointers to an entry as the API returns nil for entries managed on an enterprise level.
-func (c *Client) GetOrganizationIPAllowListEntries(ctx context.Context, organizationName string) ([]*IPAllowListEntry, error) {
-	var entries []*IPAllowListEntry
-	var err error
-	if c.cacheEntries {
-		entries, err = c.getOrganizationIPAllowListEntriesWithCache(ctx, organizationName)
-	} else {
-		entries, err = c.getOrganizationIPAllowListEntries(ctx, organizationName)
-	}
+// GetOrganizationID fetches GitHub GraphQL API node_id for given organizationName.
+// IDs are cached per organizationName for the lifetime of the client as they never change.
+func (c *Client) GetOrganizationID(ctx context.Context, organizationName string) (string, error) {
+	return c.GetOwnerID(ctx, OrganizationOwner(organizationName))
+}
 
-	return entries, err
+// GetOrganizationIPAllowListEnabledSetting fetches whether IP allow list is enabled for a given organizationName.
+func (c *Client) GetOrganizationIPAllowListEnabledSetting(ctx context.Context, organizationName string) (IPAllowListEnabledSettingValue, error) {
+	return c.GetOwnerIPAllowListEnabledSetting(ctx, OrganizationOwner(organizationName))
 }
 
-func (c *Client) getOrganizationIPAllowListEntriesWithCache(ctx context.Context, organizationName string) ([]*IPAllowListEntry, error) {
-	c.organizationEntriesCacheMutex.Lock()
-	defer c.organizationEntriesCacheMutex.Unlock()
+// GetOrganizationIPAllowListForInstalledAppsEnabledSetting fetches whether IP allow list configuration of installed GitHub Apps is inherited by a given organizationName.
+func (c *Client) GetOrganizationIPAllowListForInstalledAppsEnabledSetting(ctx context.Context, organizationName string) (IPAllowListForInstalledAppsEnabledSettingValue, error) {
+	return c.GetOwnerIPAllowListForInstalledAppsEnabledSetting(ctx, OrganizationOwner(organizationName))
+}
 
-	var entries []*IPAllowListEntry
-	var ok bool
-	entries, ok = c.organizationEntriesCache[organizationName]
-	if !ok {
-		var err error
-		entries, err = c.getOrganizationIPAllowListEntries(ctx, organizationName)
-		if err != nil {
-			return entries, errors.Wrap(err, "getOrganizationIPAllowListEntriesWithCache error")
-		}
+// InheritedIPAllowListEntries are entries of an organization managed on the level of an enterprise it belongs to.
+// GitHub lists them as nil entries of the organization, so only their number is known unless they are resolved from the enterprise.
+type InheritedIPAllowListEntries struct {
+	// Count is the number of inherited entries listed as nil entries of the organization.
+	Count int
+	// Enterprise is the name of the enterprise the entries were resolved from, or empty if they were not resolved.
+	Enterprise string
+	// Entries are the enterprise's entries, or nil if they were not resolved.
+	Entries []*IPAllowListEntry
+}
 
-		c.organizationEntriesCache[organizationName] = entries
+// CountInheritedIPAllowListEntries returns the number of nil entries, which GitHub lists for entries managed on an enterprise level.
+func CountInheritedIPAllowListEntries(entries []*IPAllowListEntry) int {
+	count := 0
+	for _, e := range entries {
+		if e == nil {
+			count++
+		}
 	}
-	return entries, nil
+	return count
 }
 
-func (c *Client) getOrganizationIPAllowListEntries(ctx context.Context, organizationName string) ([]*IPAllowListEntry, error) {
-	reqData := GraphQLRequest{Query: getOrganizationIPAllowListEntriesQuery, Variables: map[string]any{"org": organizationName}}
-	entries, err := paginate[GetOrganizationIPAllowListQueryResponse, IPAllowListEntry](ctx, c, reqData,
-		func(t *GetOrganizationIPAllowListQueryResponse) []*IPAllowListEntry {
-			return t.Organization.IPAllowListEntries.Nodes
-		}, func(t *GetOrganizationIPAllowListQueryResponse) PageInfo {
-			return t.Organization.IPAllowListEntries.PageInfo
-		})
-
+// GetOrganizationInheritedIPAllowListEntries counts entries a given organizationName inherits from its enterprise,
+// and resolves them by listing entries of enterpriseName if it is not empty and there are any inherited entries.
+// Listing entries of the enterprise requires access to the enterprise.
+func (c *Client) GetOrganizationInheritedIPAllowListEntries(ctx context.Context, organizationName string, enterpriseName string) (*InheritedIPAllowListEntries, error) {
+	entries, err := c.GetOrganizationIPAllowListEntries(ctx, organizationName)
 	if err != nil {
-		return []*IPAllowListEntry{}, errors.Wrap(err, "getOrganizationIPAllowListEntries error")
+		return nil, errors.Wrap(err, "GetOrganizationInheritedIPAllowListEntries error")
 	}
-	return entries, nil
-}
 
-// GetOrganizationID fetches GitHub GraphQL API node_id for given organizationName.
-func (c *Client) GetOrganizationID(ctx context.Context, organizationName string) (string, error) {
-	reqData := GraphQLRequest{
-		Query: getOrganizationIDQuery,
-		Variables: map[string]any{
-			"organizationName": organizationName,
-		}}
+	inherited := &InheritedIPAllowListEntries{Count: CountInheritedIPAllowListEntries(entries)}
+	if inherited.Count == 0 || enterpriseName == "" {
+		return inherited, nil
+	}
 
-	resData, err := doRequest[GetOrganizationIDQueryResponse](ctx, c, reqData)
+	enterpriseEntries, err := c.GetEnterpriseIPAllowListEntries(ctx, enterpriseName)
 	if err != nil {
-		return "", errors.Wrap(err, "GetOrganizationID error")
+		return nil, errors.Wrap(err, "GetOrganizationInheritedIPAllowListEntries error")
 	}
-
-	return resData.Organization.ID, nil
+	inherited.Enterprise = enterpriseName
+	inherited.Entries = enterpriseEntries
+	return inherited, nil
 }
@@ -2,6 +2,8 @@ package github
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -42,6 +44,22 @@ const getOrganizationIPAllowListEntriesResponseTemplate = `{
     }
 }`
 
+const getOrganizationIPAllowListEnabledSettingResponseTemplate = `{
+    "data": {
+        "organization": {
+            "ipAllowListEnabledSetting": "%s"
+        }
+    }
+}`
+
+const getOrganizationIPAllowListForInstalledAppsEnabledSettingResponseTemplate = `{
+    "data": {
+        "organization": {
+            "ipAllowListForInstalledAppsEnabledSetting": "%s"
+        }
+    }
+}`
+
 func TestGetOrganizationID(t *testing.T) {
 	// given
 	expectedOrganizationID := "abc123"
@@ -56,6 +74,25 @@ func TestGetOrganizationID(t *testing.T) {
 	assert.Equal(t, expectedOrganizationID, retrievedOrganizationID)
 }
 
+func TestGetOrganizationIDIsCachedPerOrganizationName(t *testing.T) {
+	// given
+	expectedOrganizationID := "abc123"
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(getOrganizationIDResponseWith(expectedOrganizationID), getOrganizationIDResponseWith("other-id"))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	_, _ = client.GetOrganizationID(context.TODO(), "some organization")
+	retrievedOrganizationID, err := client.GetOrganizationID(context.TODO(), "some organization")
+	otherOrganizationID, otherErr := client.GetOrganizationID(context.TODO(), "other organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, otherErr)
+	assert.Equal(t, expectedOrganizationID, retrievedOrganizationID)
+	assert.Equal(t, "other-id", otherOrganizationID)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
 func TestGetOrganizationIDWithFailingServer(t *testing.T) {
 	// given
 	expectedStatusCode := http.StatusInternalServerError
@@ -184,6 +221,40 @@ func TestGetOrganizationIPAllowListEntriesWithEntriesCachingDoesNotExecuteCallsC
 	}
 }
 
+func TestGetOrganizationIPAllowListEnabledSetting(t *testing.T) {
+	for _, expectedValue := range []IPAllowListEnabledSettingValue{IPAllowListEnabledSettingEnabled, IPAllowListEnabledSettingDisabled} {
+		t.Run(string(expectedValue), func(t *testing.T) {
+			// given
+			gitHubGraphQLAPIMock := serverReturning(fmt.Sprintf(getOrganizationIPAllowListEnabledSettingResponseTemplate, expectedValue))
+			client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+			// when
+			value, err := client.GetOrganizationIPAllowListEnabledSetting(context.TODO(), "some organization")
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, expectedValue, value)
+		})
+	}
+}
+
+func TestGetOrganizationIPAllowListForInstalledAppsEnabledSetting(t *testing.T) {
+	for _, expectedValue := range []IPAllowListForInstalledAppsEnabledSettingValue{IPAllowListForInstalledAppsEnabledSettingEnabled, IPAllowListForInstalledAppsEnabledSettingDisabled} {
+		t.Run(string(expectedValue), func(t *testing.T) {
+			// given
+			gitHubGraphQLAPIMock := serverReturning(fmt.Sprintf(getOrganizationIPAllowListForInstalledAppsEnabledSettingResponseTemplate, expectedValue))
+			client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+			// when
+			value, err := client.GetOrganizationIPAllowListForInstalledAppsEnabledSetting(context.TODO(), "some organization")
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, expectedValue, value)
+		})
+	}
+}
+
 func getOrganizationIDResponseWith(expectedOrganizationID string) string {
 	return fmt.Sprintf(getOrganizationIDResponseTemplate, expectedOrganizationID)
 }
@@ -211,3 +282,181 @@ func pagedGetOrganizationIPAllowListEntriesResponses(expectedEntries []*IPAllowL
 	lastPage := getOrganizationIPAllowListEntriesResponseLastPageWith(*expectedEntries[len(expectedEntries)-1])
 	return append(pagedResponses, lastPage)
 }
+
+const getOrganizationIPAllowListEntriesResponseWithInheritedEntries = `{
+    "data": {
+        "organization": {
+            "ipAllowListEntries": {
+                "nodes": [null, null],
+                "pageInfo": {
+                    "hasNextPage": false,
+                    "startCursor": "abc",
+                    "endCursor": "abc"
+                }
+            }
+        }
+    }
+}`
+
+func TestGetOrganizationInheritedIPAllowListEntries(t *testing.T) {
+	// given
+	enterpriseEntry := IPAllowListEntry{ID: "enterprise-entry-id", AllowListValue: "10.0.0.0/8", IsActive: true, Name: "enterprise", CreatedAt: truncateToGitHubPrecision(time.Now()), UpdatedAt: truncateToGitHubPrecision(time.Now())}
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getOrganizationIPAllowListEntriesResponseWithInheritedEntries,
+		getEnterpriseIPAllowListEntriesResponseLastPageWith(enterpriseEntry),
+	)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	inherited, err := client.GetOrganizationInheritedIPAllowListEntries(context.TODO(), "some organization", "some enterprise")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, &InheritedIPAllowListEntries{Count: 2, Enterprise: "some enterprise", Entries: []*IPAllowListEntry{&enterpriseEntry}}, inherited)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
+func TestGetOrganizationInheritedIPAllowListEntriesWithoutEnterprise(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(getOrganizationIPAllowListEntriesResponseWithInheritedEntries)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	inherited, err := client.GetOrganizationInheritedIPAllowListEntries(context.TODO(), "some organization", "")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, &InheritedIPAllowListEntries{Count: 2}, inherited)
+	assert.Equal(t, int64(1), receivedRequests.Load())
+}
+
+func TestGetOrganizationInheritedIPAllowListEntriesDoesNotListEnterpriseWithoutInheritedEntries(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	inherited, err := client.GetOrganizationInheritedIPAllowListEntries(context.TODO(), "some organization", "some enterprise")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, &InheritedIPAllowListEntries{}, inherited)
+	assert.Equal(t, int64(1), receivedRequests.Load())
+}
+
+func TestForEachOrganizationIPAllowListEntry(t *testing.T) {
+	// given
+	expectedEntries := []*IPAllowListEntry{
+		{ID: "1", AllowListValue: "1.1.1.1/32", IsActive: true, Name: "1", CreatedAt: truncateToGitHubPrecision(time.Now()), UpdatedAt: truncateToGitHubPrecision(time.Now())},
+		{ID: "2", AllowListValue: "2.2.2.2/32", IsActive: true, Name: "2", CreatedAt: truncateToGitHubPrecision(time.Now()), UpdatedAt: truncateToGitHubPrecision(time.Now())},
+		{ID: "3", AllowListValue: "3.3.3.3/32", IsActive: true, Name: "3", CreatedAt: truncateToGitHubPrecision(time.Now()), UpdatedAt: truncateToGitHubPrecision(time.Now())},
+	}
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(pagedGetOrganizationIPAllowListEntriesResponses(expectedEntries)...)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	var entries []*IPAllowListEntry
+	err := client.ForEachOrganizationIPAllowListEntry(context.TODO(), "some organization", func(e *IPAllowListEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEntries, entries)
+	assert.Equal(t, int64(3), receivedRequests.Load())
+	_, cached := client.entriesCaches[OwnerKindOrganization].peek("some organization")
+	assert.False(t, cached)
+}
+
+func TestForEachOrganizationIPAllowListEntryStopsEarly(t *testing.T) {
+	// given
+	expectedEntries := []*IPAllowListEntry{
+		{ID: "1", AllowListValue: "1.1.1.1/32", CreatedAt: truncateToGitHubPrecision(time.Now()), UpdatedAt: truncateToGitHubPrecision(time.Now())},
+		{ID: "2", AllowListValue: "2.2.2.2/32", CreatedAt: truncateToGitHubPrecision(time.Now()), UpdatedAt: truncateToGitHubPrecision(time.Now())},
+	}
+	someError := errors.New("some error")
+	tests := []struct {
+		name          string
+		returnedError error
+		expectedError error
+	}{
+		{name: "stop iteration", returnedError: ErrStopIteration},
+		{name: "error", returnedError: someError, expectedError: someError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(pagedGetOrganizationIPAllowListEntriesResponses(expectedEntries)...)
+			client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+			// when
+			calls := 0
+			err := client.ForEachOrganizationIPAllowListEntry(context.TODO(), "some organization", func(e *IPAllowListEntry) error {
+				calls++
+				return test.returnedError
+			})
+
+			// then
+			if test.expectedError != nil {
+				assert.ErrorIs(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, 1, calls)
+			assert.Equal(t, int64(1), receivedRequests.Load())
+		})
+	}
+}
+
+func TestForEachOrganizationIPAllowListEntryUsesCachedEntries(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(getOrganizationIPAllowListEntriesResponseLastPageWith(someCachedEntry))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	_, _ = client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// when
+	var entries []*IPAllowListEntry
+	err := client.ForEachOrganizationIPAllowListEntry(context.TODO(), "some organization", func(e *IPAllowListEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCachedEntry}, entries)
+	assert.Equal(t, int64(1), receivedRequests.Load())
+}
+
+func TestGetOrganizationIPAllowListEntriesWithPageSize(t *testing.T) {
+	tests := []struct {
+		name             string
+		opts             []ClientOption
+		expectedPageSize float64
+	}{
+		{name: "default", expectedPageSize: 100},
+		{name: "configured", opts: []ClientOption{WithPageSize(25)}, expectedPageSize: 25},
+		{name: "too large", opts: []ClientOption{WithPageSize(101)}, expectedPageSize: 100},
+		{name: "zero", opts: []ClientOption{WithPageSize(0)}, expectedPageSize: 100},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			var pageSize any
+			gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req GraphQLRequest
+				_ = json.NewDecoder(r.Body).Decode(&req)
+				pageSize = req.Variables["first"]
+				_, _ = w.Write([]byte(someGetOrganizationIPAllowListEntriesResponse))
+			}))
+			client := NewAuthenticatedGitHubClient(context.TODO(), "", append(test.opts, WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))...)
+
+			// when
+			_, err := client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedPageSize, pageSize)
+		})
+	}
+}
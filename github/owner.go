@@ -0,0 +1,434 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// OwnerKind is a kind of an IP allow list owner.
+type OwnerKind string
+
+const (
+	OwnerKindOrganization OwnerKind = "organization"
+	OwnerKindEnterprise   OwnerKind = "enterprise"
+	OwnerKindApp          OwnerKind = "app"
+)
+
+// ownerKinds are all kinds of IP allow list owners, GitHub's IpAllowListOwner union.
+var ownerKinds = []OwnerKind{OwnerKindOrganization, OwnerKindEnterprise, OwnerKindApp}
+
+// Owner is an organization, an enterprise or a GitHub App owning IP allow list entries.
+type Owner struct {
+	Kind OwnerKind
+	// Name is an organization's login, an enterprise's slug or a GitHub App's node ID.
+	Name string
+}
+
+// OrganizationOwner returns an owner for an organization with a given login.
+func OrganizationOwner(login string) Owner {
+	return Owner{Kind: OwnerKindOrganization, Name: login}
+}
+
+// EnterpriseOwner returns an owner for an enterprise with a given slug.
+func EnterpriseOwner(slug string) Owner {
+	return Owner{Kind: OwnerKindEnterprise, Name: slug}
+}
+
+// AppOwner returns an owner for a GitHub App with a given GraphQL node ID, e.g. as returned by `gh api /apps/my-app --jq .node_id`
+// for https://github.com/apps/my-app. GitHub's GraphQL API has no way to look up an App by its slug.
+func AppOwner(nodeID string) Owner {
+	return Owner{Kind: OwnerKindApp, Name: nodeID}
+}
+
+func (o Owner) String() string {
+	return fmt.Sprintf("%s %s", o.Kind, o.Name)
+}
+
+// ownerQueries are queries resolving an owner of a kind by its name given as a $name variable.
+// Settings queries are empty for kinds without IP allow list settings.
+type ownerQueries struct {
+	id                             string
+	entries                        string
+	enabledSetting                 string
+	forInstalledAppsEnabledSetting string
+}
+
+const ipAllowListEntriesSelection = `ipAllowListEntries(first: $first, after: $after) {
+      nodes {
+        id
+        allowListValue
+        name
+        isActive
+        createdAt
+        updatedAt
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+    }`
+
+const rateLimitSelection = `rateLimit {
+    limit
+    cost
+    remaining
+    used
+    resetAt
+  }`
+
+var ownerKindQueries = map[OwnerKind]ownerQueries{
+	OwnerKindOrganization: {
+		id: `
+query GetOrganizationId($name: String!) {
+  organization(login: $name) {
+    id
+  }
+}`,
+		entries: `
+query GetOrganizationIpAllowListEntries($name: String!, $first: Int!, $after: String) {
+  organization(login: $name) {
+    ` + ipAllowListEntriesSelection + `
+  }
+  ` + rateLimitSelection + `
+}`,
+		enabledSetting: `
+query GetOrganizationIpAllowListEnabledSetting($name: String!) {
+  organization(login: $name) {
+    ipAllowListEnabledSetting
+  }
+}`,
+		forInstalledAppsEnabledSetting: `
+query GetOrganizationIpAllowListForInstalledAppsEnabledSetting($name: String!) {
+  organization(login: $name) {
+    ipAllowListForInstalledAppsEnabledSetting
+  }
+}`,
+	},
+	OwnerKindEnterprise: {
+		id: `
+query GetEnterpriseId($name: String!) {
+  enterprise(slug: $name) {
+    id
+  }
+}`,
+		entries: `
+query GetEnterpriseIpAllowListEntries($name: String!, $first: Int!, $after: String) {
+  enterprise(slug: $name) {
+    ownerInfo {
+      ` + ipAllowListEntriesSelection + `
+    }
+  }
+  ` + rateLimitSelection + `
+}`,
+		enabledSetting: `
+query GetEnterpriseIpAllowListEnabledSetting($name: String!) {
+  enterprise(slug: $name) {
+    ownerInfo {
+      ipAllowListEnabledSetting
+    }
+  }
+}`,
+		forInstalledAppsEnabledSetting: `
+query GetEnterpriseIpAllowListForInstalledAppsEnabledSetting($name: String!) {
+  enterprise(slug: $name) {
+    ownerInfo {
+      ipAllowListForInstalledAppsEnabledSetting
+    }
+  }
+}`,
+	},
+	OwnerKindApp: {
+		id: `
+query GetAppId($name: ID!) {
+  node(id: $name) {
+    ... on App {
+      id
+    }
+  }
+}`,
+		entries: `
+query GetAppIpAllowListEntries($name: ID!, $first: Int!, $after: String) {
+  node(id: $name) {
+    ... on App {
+      id
+      ` + ipAllowListEntriesSelection + `
+    }
+  }
+  ` + rateLimitSelection + `
+}`,
+	},
+}
+
+type ipAllowListEntriesConnection struct {
+	Nodes    []*IPAllowListEntry `json:"nodes"`
+	PageInfo PageInfo            `json:"pageInfo"`
+}
+
+// ownerSettings are IP allow list fields an enterprise holds in its ownerInfo and other owners hold themselves.
+type ownerSettings struct {
+	IPAllowListEntries                        ipAllowListEntriesConnection                   `json:"ipAllowListEntries"`
+	IPAllowListEnabledSetting                 IPAllowListEnabledSettingValue                 `json:"ipAllowListEnabledSetting"`
+	IPAllowListForInstalledAppsEnabledSetting IPAllowListForInstalledAppsEnabledSettingValue `json:"ipAllowListForInstalledAppsEnabledSetting"`
+}
+
+type ownerNode struct {
+	ID string `json:"id"`
+	ownerSettings
+	OwnerInfo *ownerSettings `json:"ownerInfo"`
+}
+
+// settings returns IP allow list fields of the owner, wherever its kind holds them.
+func (o *ownerNode) settings() ownerSettings {
+	if o.OwnerInfo != nil {
+		return *o.OwnerInfo
+	}
+	return o.ownerSettings
+}
+
+// getOwnerQueryResponse is a response to any of ownerKindQueries, holding the one owner queried.
+// A GitHub App is queried as a node, which has no ID if the node ID belongs to an object of another type.
+type getOwnerQueryResponse struct {
+	Organization *ownerNode `json:"organization"`
+	Enterprise   *ownerNode `json:"enterprise"`
+	Node         *ownerNode `json:"node"`
+}
+
+func (r *getOwnerQueryResponse) owner() (*ownerNode, error) {
+	for _, o := range []*ownerNode{r.Organization, r.Enterprise} {
+		if o != nil {
+			return o, nil
+		}
+	}
+	if r.Node != nil && r.Node.ID != "" {
+		return r.Node, nil
+	}
+	return nil, errors.Wrap(ErrNotFound, "response without an owner")
+}
+
+func (r *getOwnerQueryResponse) entries() ipAllowListEntriesConnection {
+	o, err := r.owner()
+	if err != nil {
+		return ipAllowListEntriesConnection{}
+	}
+	return o.settings().IPAllowListEntries
+}
+
+func (o Owner) queries() (ownerQueries, error) {
+	queries, ok := ownerKindQueries[o.Kind]
+	if !ok {
+		return ownerQueries{}, errors.Errorf("unknown owner kind %q", o.Kind)
+	}
+	return queries, nil
+}
+
+// GetOwnerID fetches GitHub GraphQL API node_id of a given owner.
+// IDs are cached per owner for the lifetime of the client as they never change.
+func (c *Client) GetOwnerID(ctx context.Context, owner Owner) (string, error) {
+	c.ownerIDCacheMutex.Lock()
+	id, ok := c.ownerIDCache[owner]
+	c.ownerIDCacheMutex.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	queries, err := owner.queries()
+	if err != nil {
+		return "", errors.Wrap(err, "GetOwnerID error")
+	}
+	reqData := GraphQLRequest{
+		Query: queries.id,
+		Variables: map[string]any{
+			"name": owner.Name,
+		}}
+
+	resData, err := doRequest[getOwnerQueryResponse](ctx, c, reqData)
+	if err != nil {
+		return "", errors.Wrap(err, "GetOwnerID error")
+	}
+	node, err := resData.owner()
+	if err != nil {
+		return "", errors.Wrapf(err, "GetOwnerID error: no %s", owner)
+	}
+
+	c.ownerIDCacheMutex.Lock()
+	c.ownerIDCache[owner] = node.ID
+	c.ownerIDCacheMutex.Unlock()
+
+	return node.ID, nil
+}
+
+// GetOwnerIPAllowListEnabledSetting fetches whether IP allow list is enabled for a given owner.
+// Returns an error for GitHub Apps, which have no IP allow list settings.
+func (c *Client) GetOwnerIPAllowListEnabledSetting(ctx context.Context, owner Owner) (IPAllowListEnabledSettingValue, error) {
+	settings, err := c.getOwnerSettings(ctx, owner, func(q ownerQueries) string { return q.enabledSetting })
+	if err != nil {
+		return "", errors.Wrap(err, "GetOwnerIPAllowListEnabledSetting error")
+	}
+	return settings.IPAllowListEnabledSetting, nil
+}
+
+// GetOwnerIPAllowListForInstalledAppsEnabledSetting fetches whether IP allow list configuration of installed GitHub Apps is inherited by a given owner.
+// Returns an error for GitHub Apps, which have no IP allow list settings.
+func (c *Client) GetOwnerIPAllowListForInstalledAppsEnabledSetting(ctx context.Context, owner Owner) (IPAllowListForInstalledAppsEnabledSettingValue, error) {
+	settings, err := c.getOwnerSettings(ctx, owner, func(q ownerQueries) string { return q.forInstalledAppsEnabledSetting })
+	if err != nil {
+		return "", errors.Wrap(err, "GetOwnerIPAllowListForInstalledAppsEnabledSetting error")
+	}
+	return settings.IPAllowListForInstalledAppsEnabledSetting, nil
+}
+
+// getOwnerSettings fetches IP allow list settings of an owner with a query of its kind picked by query.
+func (c *Client) getOwnerSettings(ctx context.Context, owner Owner, query func(ownerQueries) string) (ownerSettings, error) {
+	queries, err := owner.queries()
+	if err != nil {
+		return ownerSettings{}, err
+	}
+	if query(queries) == "" {
+		return ownerSettings{}, errors.Errorf("%s has no IP allow list settings", owner)
+	}
+	reqData := GraphQLRequest{
+		Query: query(queries),
+		Variables: map[string]any{
+			"name": owner.Name,
+		}}
+
+	resData, err := doRequest[getOwnerQueryResponse](ctx, c, reqData)
+	if err != nil {
+		return ownerSettings{}, err
+	}
+	node, err := resData.owner()
+	if err != nil {
+		return ownerSettings{}, errors.Wrapf(err, "no %s", owner)
+	}
+	return node.settings(), nil
+}
+
+// GetOwnerIPAllowListEntries retrieves IP allow list entries of a given owner.
+// Returns a slice of pointers to an entry as the API returns nil for entries of an organization managed on an enterprise level.
+func (c *Client) GetOwnerIPAllowListEntries(ctx context.Context, owner Owner) ([]*IPAllowListEntry, error) {
+	if !c.cacheEntries {
+		return c.getOwnerIPAllowListEntries(ctx, owner)
+	}
+	cache, err := c.entriesCache(owner)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetOwnerIPAllowListEntries error")
+	}
+	entries, err := cache.getOrFetch(ctx, owner.Name, c.ownerEntriesFetcher(owner))
+	if err != nil {
+		return entries, errors.Wrap(err, "GetOwnerIPAllowListEntries error")
+	}
+	return entries, nil
+}
+
+// GetOwnerIPAllowListEntryByID returns an entry with a given entryID among IP allow list entries of a given owner.
+// Entries are listed like with GetOwnerIPAllowListEntries, and looked up without scanning them when they are cached.
+// Returns an error matching ErrNotFound if there is no such entry, and a ListingError if entries cannot be listed, e.g. as the owner does not exist.
+func (c *Client) GetOwnerIPAllowListEntryByID(ctx context.Context, owner Owner, entryID string) (*IPAllowListEntry, error) {
+	index, err := c.getOwnerIPAllowListEntriesIndex(ctx, owner)
+	if err != nil {
+		return nil, errors.Wrap(ListingError{Owner: owner, Err: err}, "GetOwnerIPAllowListEntryByID error")
+	}
+	entry := index.entryByID(entryID)
+	if entry == nil {
+		return nil, errors.Wrapf(ErrNotFound, "entry %s of %s", entryID, owner)
+	}
+	return entry, nil
+}
+
+// GetOwnerIPAllowListEntriesByCIDR returns IP allow list entries of a given owner with a value equal to a given value regardless of its notation,
+// e.g. 1.2.3.4 and 1.2.3.4/32. Entries are listed like with GetOwnerIPAllowListEntries, and looked up without scanning them when they are cached.
+func (c *Client) GetOwnerIPAllowListEntriesByCIDR(ctx context.Context, owner Owner, value CIDR) ([]*IPAllowListEntry, error) {
+	index, err := c.getOwnerIPAllowListEntriesIndex(ctx, owner)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetOwnerIPAllowListEntriesByCIDR error")
+	}
+	return index.entriesByCIDR(value), nil
+}
+
+// ForEachOwnerIPAllowListEntry calls fn with each IP allow list entry of a given owner, in the order GitHub lists them.
+// Entries are requested page by page as fn consumes them, so memory stays flat regardless of the number of entries, unless fresh entries are cached,
+// in which case fn is called with cached entries. Streamed entries are not cached.
+// fn is called with nil for entries of an organization managed on an enterprise level, like GetOwnerIPAllowListEntries returns them.
+// Iteration stops at the first error returned by fn, which is returned unless it is ErrStopIteration.
+func (c *Client) ForEachOwnerIPAllowListEntry(ctx context.Context, owner Owner, fn func(*IPAllowListEntry) error) error {
+	queries, err := owner.queries()
+	if err != nil {
+		return errors.Wrap(err, "ForEachOwnerIPAllowListEntry error")
+	}
+
+	if c.cacheEntries {
+		if entries, ok := c.entriesCaches[owner.Kind].peek(owner.Name); ok {
+			err := forEachEntry(entries, fn)
+			if err != nil && !errors.Is(err, ErrStopIteration) {
+				return errors.Wrap(err, "ForEachOwnerIPAllowListEntry error")
+			}
+			return nil
+		}
+	}
+
+	reqData := GraphQLRequest{Query: queries.entries, Variables: map[string]any{"name": owner.Name}}
+	err = forEachPage[getOwnerQueryResponse, IPAllowListEntry](ctx, c, reqData,
+		func(t *getOwnerQueryResponse) []*IPAllowListEntry {
+			return t.entries().Nodes
+		}, func(t *getOwnerQueryResponse) PageInfo {
+			return t.entries().PageInfo
+		}, func(entries []*IPAllowListEntry) error {
+			return forEachEntry(entries, fn)
+		})
+	if err != nil {
+		return errors.Wrap(err, "ForEachOwnerIPAllowListEntry error")
+	}
+	return nil
+}
+
+func (c *Client) getOwnerIPAllowListEntriesIndex(ctx context.Context, owner Owner) (*entriesIndex, error) {
+	if !c.cacheEntries {
+		entries, err := c.getOwnerIPAllowListEntries(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+		return newEntriesIndex(entries), nil
+	}
+	cache, err := c.entriesCache(owner)
+	if err != nil {
+		return nil, err
+	}
+	return cache.getOrFetchIndex(ctx, owner.Name, c.ownerEntriesFetcher(owner))
+}
+
+// entriesCache returns a cache of entries of owners of the owner's kind.
+func (c *Client) entriesCache(owner Owner) (*entriesCache, error) {
+	cache, ok := c.entriesCaches[owner.Kind]
+	if !ok {
+		return nil, errors.Errorf("unknown owner kind %q", owner.Kind)
+	}
+	return cache, nil
+}
+
+// ownerEntriesFetcher adapts getOwnerIPAllowListEntries to entriesCache, which keys entries of owners of one kind by their names.
+func (c *Client) ownerEntriesFetcher(owner Owner) func(context.Context, string) ([]*IPAllowListEntry, error) {
+	return func(ctx context.Context, name string) ([]*IPAllowListEntry, error) {
+		return c.getOwnerIPAllowListEntries(ctx, Owner{Kind: owner.Kind, Name: name})
+	}
+}
+
+func (c *Client) getOwnerIPAllowListEntries(ctx context.Context, owner Owner) ([]*IPAllowListEntry, error) {
+	queries, err := owner.queries()
+	if err != nil {
+		return []*IPAllowListEntry{}, errors.Wrap(err, "getOwnerIPAllowListEntries error")
+	}
+	reqData := GraphQLRequest{Query: queries.entries, Variables: map[string]any{"name": owner.Name}}
+	entries, err := paginate[getOwnerQueryResponse, IPAllowListEntry](ctx, c, reqData,
+		func(t *getOwnerQueryResponse) []*IPAllowListEntry {
+			return t.entries().Nodes
+		}, func(t *getOwnerQueryResponse) PageInfo {
+			return t.entries().PageInfo
+		})
+
+	if err != nil {
+		return []*IPAllowListEntry{}, errors.Wrap(err, "getOwnerIPAllowListEntries error")
+	}
+	return entries, nil
+}
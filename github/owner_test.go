@@ -0,0 +1,135 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const getAppIPAllowListEntriesResponseTemplate = `{
+    "data": {
+        "node": {
+            "id": "A_abc",
+            "ipAllowListEntries": {
+                "nodes": [
+                    {
+                        "id": "%s",
+                        "allowListValue": "%s",
+                        "name": "%s",
+                        "isActive": %t,
+                        "createdAt": "%s",
+                        "updatedAt": "%s"
+                    }
+                ],
+                "pageInfo": {
+                    "hasNextPage": false,
+                    "endCursor": "abc"
+                }
+            }
+        }
+    }
+}`
+
+func getAppIPAllowListEntriesResponseWith(expectedEntry IPAllowListEntry) string {
+	return fmt.Sprintf(getAppIPAllowListEntriesResponseTemplate, expectedEntry.ID, expectedEntry.AllowListValue, expectedEntry.Name, expectedEntry.IsActive, expectedEntry.CreatedAt.Format(gitHubTimeFormat), expectedEntry.UpdatedAt.Format(gitHubTimeFormat))
+}
+
+func TestGetOwnerIPAllowListEntriesOfApp(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := serverReturning(getAppIPAllowListEntriesResponseWith(someCachedEntry))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	entries, err := client.GetOwnerIPAllowListEntries(context.TODO(), AppOwner("A_abc"))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCachedEntry}, entries)
+}
+
+func TestGetOwnerIDOfAppCallsAPIOnlyOnce(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(`{"data": {"node": {"id": "A_abc"}}}`)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	_, _ = client.GetOwnerID(context.TODO(), AppOwner("A_abc"))
+
+	// when
+	id, err := client.GetOwnerID(context.TODO(), AppOwner("A_abc"))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "A_abc", id)
+	assert.Equal(t, int64(1), receivedRequests.Load())
+}
+
+func TestGetOwnerIDOfMissingOwner(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := serverReturning(`{"data": {"node": null}}`)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	_, err := client.GetOwnerID(context.TODO(), AppOwner("A_missing"))
+
+	// then
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestGetOwnerIDOfNodeOtherThanApp(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := serverReturning(`{"data": {"node": {}}}`)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	_, err := client.GetOwnerID(context.TODO(), AppOwner("O_abc"))
+
+	// then
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestGetOwnerIPAllowListEntriesOfUnknownOwnerKind(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses()
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	_, err := client.GetOwnerIPAllowListEntries(context.TODO(), Owner{Kind: "user", Name: "some user"})
+
+	// then
+	assert.ErrorContains(t, err, `unknown owner kind "user"`)
+	assert.Equal(t, int64(0), receivedRequests.Load())
+}
+
+func TestCreateIPAllowListEntryAddsEntryToAppCache(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses(
+		getAppIPAllowListEntriesResponseWith(someCachedEntry),
+		createEntryResponseWithOwner(someCreatedEntry, `{"__typename": "App", "id": "A_abc"}`),
+	)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	_, _ = client.GetOwnerIPAllowListEntries(context.TODO(), AppOwner("A_abc"))
+
+	// when
+	_, err := client.CreateIPAllowListEntry(context.TODO(), "some owner", someCreatedEntry.Name, someCreatedEntry.AllowListValue, someCreatedEntry.IsActive)
+	entries, _ := client.GetOwnerIPAllowListEntries(context.TODO(), AppOwner("A_abc"))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*IPAllowListEntry{&someCachedEntry, &someCreatedEntry}, entries)
+	assert.Equal(t, int64(2), receivedRequests.Load())
+}
+
+func TestGetOwnerIPAllowListEnabledSettingOfApp(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, receivedRequests := serverReturningConsecutiveResponses()
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	_, err := client.GetOwnerIPAllowListEnabledSetting(context.TODO(), AppOwner("A_abc"))
+
+	// then
+	assert.ErrorContains(t, err, "app A_abc has no IP allow list settings")
+	assert.Equal(t, int64(0), receivedRequests.Load())
+}
@@ -0,0 +1,150 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RateLimit is a snapshot of GitHub's GraphQL API rate limit budget reported with the latest response.
+type RateLimit struct {
+	// Limit is the maximum number of points that can be used per hour.
+	Limit int
+	// Remaining is the number of points remaining until Reset.
+	Remaining int
+	// Used is the number of points used since the last reset.
+	Used int
+	// Reset is when the budget is restored to Limit.
+	Reset time.Time
+	// Cost is the number of points used by the latest query reporting its rateLimit field, or 0 if no query reported it.
+	Cost int
+}
+
+type graphQLRateLimit struct {
+	Limit     int       `json:"limit"`
+	Cost      int       `json:"cost"`
+	Remaining int       `json:"remaining"`
+	Used      int       `json:"used"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// WithRateLimitThreshold makes the client pause requests until the rate limit is reset once fewer than threshold points remain,
+// instead of using up the budget and failing. By default, requests are never paused.
+// threshold must be >= 0, otherwise the option is ignored.
+func WithRateLimitThreshold(threshold int) ClientOption {
+	return func(options *ClientOptions) {
+		if threshold >= 0 {
+			options.rateLimitThreshold = threshold
+		}
+	}
+}
+
+// RateLimit returns the rate limit budget reported with the latest response.
+// Returns false if no response reported it yet.
+func (c *Client) RateLimit() (RateLimit, bool) {
+	return c.rateLimiter.get()
+}
+
+// rateLimiter tracks the rate limit budget reported with X-RateLimit-* headers and the GraphQL rateLimit field of responses.
+type rateLimiter struct {
+	mutex     *sync.Mutex
+	rateLimit RateLimit
+	known     bool
+	threshold int
+	now       func() time.Time
+}
+
+func newRateLimiter(threshold int) *rateLimiter {
+	return &rateLimiter{
+		mutex:     &sync.Mutex{},
+		threshold: threshold,
+		now:       time.Now,
+	}
+}
+
+func (l *rateLimiter) get() (RateLimit, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.rateLimit, l.known
+}
+
+// update records a reported budget. Responses to concurrent requests may arrive out of order,
+// so within the same rate limit window the lowest remaining budget is kept.
+func (l *rateLimiter) update(rateLimit RateLimit) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.known && rateLimit.Reset.Equal(l.rateLimit.Reset) && rateLimit.Remaining > l.rateLimit.Remaining {
+		return
+	}
+	if l.known && rateLimit.Reset.Before(l.rateLimit.Reset) {
+		return
+	}
+	if rateLimit.Cost == 0 && rateLimit.Reset.Equal(l.rateLimit.Reset) {
+		rateLimit.Cost = l.rateLimit.Cost
+	}
+	l.rateLimit = rateLimit
+	l.known = true
+}
+
+// updateFromHeaders records a budget reported with X-RateLimit-* headers, if a response has them.
+func (l *rateLimiter) updateFromHeaders(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	limit, _ := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	used, _ := strconv.Atoi(header.Get("X-RateLimit-Used"))
+
+	l.update(RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Used:      used,
+		Reset:     time.Unix(reset, 0),
+	})
+}
+
+// updateFromGraphQLResponse records a budget reported with the rateLimit field of a query, if it is queried.
+func (l *rateLimiter) updateFromGraphQLResponse(gqlRes *GraphQLResponse) {
+	resData := struct {
+		RateLimit *graphQLRateLimit `json:"rateLimit"`
+	}{}
+	err := json.Unmarshal(gqlRes.Data, &resData)
+	if err != nil || resData.RateLimit == nil {
+		return
+	}
+
+	l.update(RateLimit{
+		Limit:     resData.RateLimit.Limit,
+		Remaining: resData.RateLimit.Remaining,
+		Used:      resData.RateLimit.Used,
+		Reset:     resData.RateLimit.ResetAt,
+		Cost:      resData.RateLimit.Cost,
+	})
+}
+
+// wait pauses until the rate limit is reset if fewer than threshold points remain.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mutex.Lock()
+	pause := l.known && l.rateLimit.Remaining < l.threshold
+	until := l.rateLimit.Reset.Sub(l.now())
+	l.mutex.Unlock()
+
+	if !pause || until <= 0 {
+		return nil
+	}
+	err := sleep(ctx, until)
+	if err != nil {
+		return errors.Wrap(err, "rate limit wait error")
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const someGetOrganizationIPAllowListEntriesResponseWithRateLimitTemplate = `{
+    "data": {
+        "organization": {
+            "ipAllowListEntries": {
+                "nodes": [],
+                "pageInfo": {
+                    "hasNextPage": false,
+                    "startCursor": null,
+                    "endCursor": null
+                }
+            }
+        },
+        "rateLimit": {
+            "limit": 5000,
+            "cost": 1,
+            "remaining": 4321,
+            "used": 679,
+            "resetAt": "%s"
+        }
+    }
+}`
+
+func TestClientRateLimitFromHeaders(t *testing.T) {
+	// given
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Set("X-RateLimit-Used", "1")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		_, _ = w.Write([]byte(someGetOrganizationIDResponse))
+	}))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	_, err := client.GetOrganizationID(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	rateLimit, ok := client.RateLimit()
+	assert.True(t, ok)
+	assert.Equal(t, RateLimit{Limit: 5000, Remaining: 4999, Used: 1, Reset: reset}, rateLimit)
+}
+
+func TestClientRateLimitFromGraphQLResponse(t *testing.T) {
+	// given
+	reset := truncateToGitHubPrecision(time.Now().Add(time.Hour))
+	gitHubGraphQLAPIMock := serverReturning(fmt.Sprintf(someGetOrganizationIPAllowListEntriesResponseWithRateLimitTemplate, reset.Format(gitHubTimeFormat)))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	_, err := client.GetOrganizationIPAllowListEntries(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	rateLimit, ok := client.RateLimit()
+	assert.True(t, ok)
+	assert.Equal(t, RateLimit{Limit: 5000, Remaining: 4321, Used: 679, Reset: reset, Cost: 1}, rateLimit)
+}
+
+func TestClientRateLimitIsUnknownWithoutResponses(t *testing.T) {
+	// given
+	client := NewAuthenticatedGitHubClient(context.TODO(), "")
+
+	// when
+	_, ok := client.RateLimit()
+
+	// then
+	assert.False(t, ok)
+}
+
+func TestRateLimiterKeepsLowestRemainingWithinTheSameWindow(t *testing.T) {
+	// given
+	reset := time.Now().Add(time.Hour)
+	limiter := newRateLimiter(0)
+
+	// when
+	limiter.update(RateLimit{Remaining: 10, Reset: reset})
+	limiter.update(RateLimit{Remaining: 12, Reset: reset})
+	limiter.update(RateLimit{Remaining: 5000, Reset: reset.Add(-time.Hour)})
+
+	// then
+	rateLimit, _ := limiter.get()
+	assert.Equal(t, 10, rateLimit.Remaining)
+
+	// and when
+	limiter.update(RateLimit{Remaining: 4999, Reset: reset.Add(time.Hour)})
+
+	// then
+	rateLimit, _ = limiter.get()
+	assert.Equal(t, 4999, rateLimit.Remaining)
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name          string
+		rateLimit     *RateLimit
+		expectedPause bool
+	}{
+		{name: "unknown rate limit"},
+		{name: "enough remaining", rateLimit: &RateLimit{Remaining: 100, Reset: now.Add(time.Hour)}},
+		{name: "below threshold", rateLimit: &RateLimit{Remaining: 9, Reset: now.Add(time.Hour)}, expectedPause: true},
+		{name: "below threshold already reset", rateLimit: &RateLimit{Remaining: 9, Reset: now.Add(-time.Second)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			limiter := newRateLimiter(10)
+			limiter.now = func() time.Time { return now }
+			if test.rateLimit != nil {
+				limiter.update(*test.rateLimit)
+			}
+			ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+			defer cancel()
+
+			// when
+			err := limiter.wait(ctx)
+
+			// then
+			assert.Equal(t, test.expectedPause, err != nil)
+		})
+	}
+}
+
+func TestClientPausesRequestsBelowRateLimitThreshold(t *testing.T) {
+	// given
+	reset := time.Now().Add(time.Second).Truncate(time.Second).Add(time.Second)
+	var requestTimes []time.Time
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestTimes = append(requestTimes, time.Now())
+		w.Header().Set("X-RateLimit-Remaining", "1")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		_, _ = w.Write([]byte(someGetOrganizationIDResponse))
+	}))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithRateLimitThreshold(2))
+
+	// when
+	_, _ = client.GetOrganizationID(context.TODO(), "some organization")
+	_, err := client.GetOrganizationID(context.TODO(), "other organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, requestTimes, 2)
+	assert.False(t, requestTimes[1].Before(reset))
+}
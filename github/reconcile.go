@@ -0,0 +1,146 @@
+package github
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// IPAllowListPlan holds changes making an owner's IP allow list match desired entries, computed with PlanIPAllowListChanges.
+type IPAllowListPlan struct {
+	// Creates holds desired entries without a matching current entry, in the order they were desired.
+	Creates []IPAllowListEntryParameters
+	// Updates holds current entries whose name or activity differ from the desired entry they match, in the order they were listed.
+	Updates []IPAllowListPlannedUpdate
+	// Deletes holds owned current entries which are not desired or duplicate a desired entry, in the order they were listed.
+	Deletes []*IPAllowListEntry
+}
+
+// IPAllowListPlannedUpdate is a current entry to be updated to the parameters of a desired entry.
+type IPAllowListPlannedUpdate struct {
+	Entry      *IPAllowListEntry
+	Parameters IPAllowListEntryParameters
+}
+
+// IsEmpty reports whether the plan holds no changes, i.e. current entries already match desired entries.
+func (p *IPAllowListPlan) IsEmpty() bool {
+	return len(p.Creates) == 0 && len(p.Updates) == 0 && len(p.Deletes) == 0
+}
+
+// UpdatesByID returns parameters of planned updates keyed by entry IDs, as ApplyIPAllowListChanges takes them.
+func (p *IPAllowListPlan) UpdatesByID() map[string]IPAllowListEntryParameters {
+	updates := make(map[string]IPAllowListEntryParameters, len(p.Updates))
+	for _, u := range p.Updates {
+		updates[u.Entry.ID] = u.Parameters
+	}
+	return updates
+}
+
+// DeletedIDs returns IDs of planned deletes, as ApplyIPAllowListChanges takes them.
+func (p *IPAllowListPlan) DeletedIDs() []string {
+	ids := make([]string, 0, len(p.Deletes))
+	for _, e := range p.Deletes {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+type planOptions struct {
+	owned func(*IPAllowListEntry) bool
+}
+
+// PlanOption customizes PlanIPAllowListChanges.
+type PlanOption func(options *planOptions)
+
+// WithOwnedEntries limits deletes to current entries for which owned returns true, leaving other entries as they are
+// unless they match a desired entry. By default, all entries are owned. The last ownership option given takes effect.
+func WithOwnedEntries(owned func(entry *IPAllowListEntry) bool) PlanOption {
+	return func(options *planOptions) {
+		if owned != nil {
+			options.owned = owned
+		}
+	}
+}
+
+// WithOwnedNames limits deletes to current entries with names for which owned returns true, e.g. names with a prefix of a tool managing them,
+// leaving other entries as they are unless they match a desired entry. By default, all entries are owned. The last ownership option given takes effect.
+func WithOwnedNames(owned func(name string) bool) PlanOption {
+	return func(options *planOptions) {
+		if owned != nil {
+			options.owned = func(entry *IPAllowListEntry) bool {
+				return owned(entry.Name)
+			}
+		}
+	}
+}
+
+// PlanIPAllowListChanges computes changes making current entries of an owner match desired entries.
+// Entries are matched by their normalized CIDR, so 1.2.3.4 matches 1.2.3.4/32, and nil entries managed on an enterprise level are ignored.
+// A desired entry matches an owned current entry if there is one, otherwise any current entry, which is updated if its name or activity differ.
+// Other owned entries are deleted, including duplicates of a desired entry, and desired entries without a match are created.
+// Returns an error if more than one desired entry has the same normalized CIDR.
+func PlanIPAllowListChanges(desired []IPAllowListEntryParameters, current []*IPAllowListEntry, opts ...PlanOption) (*IPAllowListPlan, error) {
+	options := &planOptions{
+		owned: func(*IPAllowListEntry) bool { return true },
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	desiredByCIDR := make(map[CIDR]IPAllowListEntryParameters, len(desired))
+	for _, params := range desired {
+		value := NormalizedCIDR(params.Value)
+		if _, ok := desiredByCIDR[value]; ok {
+			return nil, errors.Errorf("PlanIPAllowListChanges error: value %s is desired more than once", params.Value)
+		}
+		desiredByCIDR[value] = params
+	}
+
+	matches := make(map[CIDR]*IPAllowListEntry, len(desired))
+	for _, e := range current {
+		if e == nil {
+			continue
+		}
+		value := NormalizedCIDR(e.AllowListValue)
+		if _, ok := desiredByCIDR[value]; !ok {
+			continue
+		}
+		if match, ok := matches[value]; !ok || (!options.owned(match) && options.owned(e)) {
+			matches[value] = e
+		}
+	}
+
+	plan := &IPAllowListPlan{}
+	for _, e := range current {
+		if e == nil {
+			continue
+		}
+		value := NormalizedCIDR(e.AllowListValue)
+		if matches[value] == e {
+			params := desiredByCIDR[value]
+			if e.Name != params.Name || e.IsActive != params.IsActive {
+				plan.Updates = append(plan.Updates, IPAllowListPlannedUpdate{Entry: e, Parameters: params})
+			}
+			continue
+		}
+		if options.owned(e) {
+			plan.Deletes = append(plan.Deletes, e)
+		}
+	}
+	for _, params := range desired {
+		if _, ok := matches[NormalizedCIDR(params.Value)]; !ok {
+			plan.Creates = append(plan.Creates, params)
+		}
+	}
+	return plan, nil
+}
+
+// ApplyIPAllowListPlan executes a plan for a given ownerID with ApplyIPAllowListChanges, which batches its changes,
+// reports results and errors per change and keeps cached entries up to date.
+func (c *Client) ApplyIPAllowListPlan(ctx context.Context, ownerID string, plan *IPAllowListPlan) (*IPAllowListChangesResult, error) {
+	result, err := c.ApplyIPAllowListChanges(ctx, ownerID, plan.Creates, plan.UpdatesByID(), plan.DeletedIDs())
+	if err != nil {
+		return result, errors.Wrap(err, "ApplyIPAllowListPlan error")
+	}
+	return result, nil
+}
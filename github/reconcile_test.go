@@ -0,0 +1,114 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanIPAllowListChanges(t *testing.T) {
+	desired := []IPAllowListEntryParameters{
+		{Name: "tool: unchanged", Value: "1.1.1.1/32", IsActive: true},
+		{Name: "tool: renamed", Value: "2.2.2.2/32", IsActive: true},
+		{Name: "tool: created", Value: "3.3.3.3/32", IsActive: false},
+		{Name: "tool: adopted", Value: "6.6.6.6/32", IsActive: true},
+	}
+	unchanged := &IPAllowListEntry{ID: "unchanged", AllowListValue: "1.1.1.1", Name: "tool: unchanged", IsActive: true}
+	duplicate := &IPAllowListEntry{ID: "duplicate", AllowListValue: "1.1.1.1/32", Name: "tool: unchanged", IsActive: true}
+	renamed := &IPAllowListEntry{ID: "renamed", AllowListValue: "2.2.2.2/32", Name: "tool: old name", IsActive: true}
+	removed := &IPAllowListEntry{ID: "removed", AllowListValue: "4.4.4.4/32", Name: "tool: removed", IsActive: true}
+	unmanaged := &IPAllowListEntry{ID: "unmanaged", AllowListValue: "5.5.5.5/32", Name: "added manually", IsActive: true}
+	adopted := &IPAllowListEntry{ID: "adopted", AllowListValue: "6.6.6.6/32", Name: "added manually", IsActive: true}
+	current := []*IPAllowListEntry{nil, unchanged, duplicate, renamed, removed, unmanaged, adopted}
+
+	t.Run("all entries owned", func(t *testing.T) {
+		// when
+		plan, err := PlanIPAllowListChanges(desired, current)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, []IPAllowListEntryParameters{desired[2]}, plan.Creates)
+		assert.Equal(t, []IPAllowListPlannedUpdate{{Entry: renamed, Parameters: desired[1]}, {Entry: adopted, Parameters: desired[3]}}, plan.Updates)
+		assert.Equal(t, []*IPAllowListEntry{duplicate, removed, unmanaged}, plan.Deletes)
+	})
+
+	t.Run("entries owned by name", func(t *testing.T) {
+		// when
+		plan, err := PlanIPAllowListChanges(desired, current, WithOwnedNames(func(name string) bool {
+			return strings.HasPrefix(name, "tool: ")
+		}))
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, []IPAllowListEntryParameters{desired[2]}, plan.Creates)
+		assert.Equal(t, []IPAllowListPlannedUpdate{{Entry: renamed, Parameters: desired[1]}, {Entry: adopted, Parameters: desired[3]}}, plan.Updates)
+		assert.Equal(t, []*IPAllowListEntry{duplicate, removed}, plan.Deletes)
+	})
+}
+
+func TestPlanIPAllowListChangesPrefersOwnedEntries(t *testing.T) {
+	// given
+	desired := []IPAllowListEntryParameters{{Name: "tool: owned", Value: "1.1.1.1/32", IsActive: true}}
+	unowned := &IPAllowListEntry{ID: "unowned", AllowListValue: "1.1.1.1/32", Name: "added manually", IsActive: true}
+	owned := &IPAllowListEntry{ID: "owned", AllowListValue: "1.1.1.1", Name: "tool: owned", IsActive: true}
+
+	// when
+	plan, err := PlanIPAllowListChanges(desired, []*IPAllowListEntry{unowned, owned}, WithOwnedNames(func(name string) bool {
+		return strings.HasPrefix(name, "tool: ")
+	}))
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, plan.IsEmpty())
+}
+
+func TestPlanIPAllowListChangesWithDuplicateDesiredValues(t *testing.T) {
+	// given
+	desired := []IPAllowListEntryParameters{{Value: "1.1.1.1"}, {Value: "1.1.1.1/32"}}
+
+	// when
+	_, err := PlanIPAllowListChanges(desired, nil)
+
+	// then
+	assert.ErrorContains(t, err, "value 1.1.1.1/32 is desired more than once")
+}
+
+func TestApplyIPAllowListPlan(t *testing.T) {
+	// given
+	updated := IPAllowListEntry{ID: "updated-id", AllowListValue: "2.2.2.2/32", IsActive: true, Name: "updated", CreatedAt: truncateToGitHubPrecision(time.Now()), UpdatedAt: truncateToGitHubPrecision(time.Now())}
+	var requests []GraphQLRequest
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"data": {"d0": null, "u0": %s, "c0": %s}, "errors": [
+			{"type": "NOT_FOUND", "path": ["d0"], "message": "Could not resolve to a node with the global id of 'deleted-id'"}
+		]}`, batchedEntryResponse(updated), batchedEntryResponse(someCreatedEntry))))
+	}))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+	plan := &IPAllowListPlan{
+		Creates: []IPAllowListEntryParameters{{Name: someCreatedEntry.Name, Value: someCreatedEntry.AllowListValue, IsActive: someCreatedEntry.IsActive}},
+		Updates: []IPAllowListPlannedUpdate{{Entry: &IPAllowListEntry{ID: "updated-id"}, Parameters: IPAllowListEntryParameters{Name: "updated", Value: "2.2.2.2/32", IsActive: true}}},
+		Deletes: []*IPAllowListEntry{{ID: "deleted-id"}},
+	}
+
+	// when
+	result, err := client.ApplyIPAllowListPlan(context.TODO(), "some owner", plan)
+
+	// then
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, result.Deletes["deleted-id"].Err, ErrNotFound)
+	assert.Equal(t, IPAllowListChangeResult{Entry: &updated}, result.Updates["updated-id"])
+	assert.Equal(t, []IPAllowListChangeResult{{Entry: &someCreatedEntry}}, result.Creates)
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "deleted-id", requests[0].Variables["d0_entryId"])
+	assert.Equal(t, "updated-id", requests[0].Variables["u0_entryId"])
+	assert.Equal(t, "some owner", requests[0].Variables["c0_ownerId"])
+}
@@ -0,0 +1,179 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// secondaryRateLimitWait is how long GitHub asks to wait after exceeding a secondary rate limit if a response does not say otherwise.
+const secondaryRateLimitWait = time.Minute
+
+// defaultMaxBackoff caps a wait between retries if a RetryPolicy does not set MaxBackoff.
+const defaultMaxBackoff = time.Minute
+
+// maxInspectedBodySize limits how much of a 403 response body is read to detect an exceeded rate limit.
+const maxInspectedBodySize = 64 * 1024
+
+// RetryPolicy determines how failed requests to GitHub's GraphQL API are retried.
+// Queries are retried on network errors, 5xx and 429 responses, and 403 responses caused by exceeded rate limits.
+// Mutations are not idempotent, so they are only retried if they were never executed: if a connection to GitHub could not be established,
+// or if GitHub rejected them because of an exceeded rate limit.
+// The zero value does not retry requests.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries of a single request.
+	MaxRetries int
+	// MinBackoff is a wait before the first retry. It doubles with each following retry, with jitter added.
+	MinBackoff time.Duration
+	// MaxBackoff caps a wait between retries. A wait requested by GitHub with a Retry-After or an X-RateLimit-Reset header
+	// of a 403, 429 or 5xx response is honoured unless it is longer than MaxBackoff, in which case the response is returned without retrying.
+	// Zero defaults to a minute, or to MinBackoff if it is longer.
+	MaxBackoff time.Duration
+}
+
+// WithRetryPolicy sets how failed requests are retried. By default, requests are not retried.
+// MaxRetries, MinBackoff and MaxBackoff must be >= 0 and a non-zero MaxBackoff must be >= MinBackoff, otherwise the option is ignored.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(options *ClientOptions) {
+		if policy.MaxBackoff == 0 {
+			policy.MaxBackoff = defaultMaxBackoff
+			if policy.MinBackoff > policy.MaxBackoff {
+				policy.MaxBackoff = policy.MinBackoff
+			}
+		}
+		if policy.MaxRetries >= 0 && policy.MinBackoff >= 0 && policy.MaxBackoff >= policy.MinBackoff {
+			options.retryPolicy = policy
+		}
+	}
+}
+
+// backoff returns a jittered exponential wait before a retry following a given number of attempts.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.MinBackoff
+	for i := 0; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// retryWait decides if a request is retried following a given number of attempts which ended with res or err.
+// A request which is not idempotent is only retried if it was never sent or was rejected because of an exceeded rate limit,
+// since GitHub rejects such requests before executing them. Returns how long to wait before the retry.
+func (p RetryPolicy) retryWait(ctx context.Context, attempt int, idempotent bool, res *http.Response, err error, now time.Time) (time.Duration, bool) {
+	if attempt >= p.MaxRetries || ctx.Err() != nil {
+		return 0, false
+	}
+	if !idempotent && !isNotSent(err) && !isRateLimited(res) {
+		return 0, false
+	}
+	if err != nil {
+		return p.backoff(attempt), true
+	}
+
+	if res.StatusCode < 500 && res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusForbidden {
+		return 0, false
+	}
+	requested, ok := requestedWait(res, now)
+	switch {
+	case ok:
+	case res.StatusCode == http.StatusForbidden:
+		if !isSecondaryRateLimit(res) {
+			return 0, false
+		}
+		requested = secondaryRateLimitWait
+	default:
+		return p.backoff(attempt), true
+	}
+
+	if requested > p.MaxBackoff {
+		return 0, false
+	}
+	return requested, true
+}
+
+// requestedWait returns a wait requested by GitHub with a Retry-After header, or with an X-RateLimit-Reset header once a rate limit is exceeded.
+// It is only consulted for 403, 429 and 5xx responses, so that headers of successful responses never cause a retry.
+func requestedWait(res *http.Response, now time.Time) (time.Duration, bool) {
+	if retryAfter := res.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(retryAfter); err == nil {
+			return nonNegative(at.Sub(now)), true
+		}
+	}
+	if res.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return nonNegative(time.Unix(reset, 0).Sub(now)), true
+		}
+	}
+	return 0, false
+}
+
+// isNotSent reports whether err shows that a request was never sent, since a connection to GitHub could not be established.
+func isNotSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isMutation reports whether a GraphQL document is a mutation, which is not idempotent.
+func isMutation(query string) bool {
+	return strings.HasPrefix(strings.TrimSpace(query), "mutation")
+}
+
+// isRateLimited reports whether a response rejects a request because of an exceeded rate limit. Returns false without a response.
+func isRateLimited(res *http.Response) bool {
+	return res != nil && statusCodeErrorKind(res, peekBody(res)) == ErrRateLimited
+}
+
+// isSecondaryRateLimit detects a secondary rate limit by a message in a response body.
+func isSecondaryRateLimit(res *http.Response) bool {
+	return strings.Contains(strings.ToLower(string(peekBody(res))), "secondary rate limit")
+}
+
+// peekBody reads the beginning of a response body and restores the body, so that it can be read again.
+// Returns nil if the body cannot be read.
+func peekBody(res *http.Response) []byte {
+	b, err := io.ReadAll(io.LimitReader(res.Body, maxInspectedBodySize))
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), res.Body), res.Body}
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,311 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const secondaryRateLimitResponse = `{"message": "You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`
+
+var someRetryPolicy = RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+func TestClientRetriesFailedRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+	}{
+		{name: "bad gateway", statusCode: http.StatusBadGateway},
+		{name: "service unavailable", statusCode: http.StatusServiceUnavailable},
+		{name: "too many requests", statusCode: http.StatusTooManyRequests},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			gitHubGraphQLAPIMock, requests := serverFailingBeforeResponding(2, test.statusCode, nil, test.body, someGetOrganizationIDResponse)
+			client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithRetryPolicy(someRetryPolicy))
+
+			// when
+			id, err := client.GetOrganizationID(context.TODO(), "some organization")
+
+			// then
+			assert.NoError(t, err)
+			assert.NotEmpty(t, id)
+			assert.Equal(t, int64(3), requests.Load())
+		})
+	}
+}
+
+func TestClientGivesUpAfterMaxRetries(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, requests := serverFailingBeforeResponding(10, http.StatusBadGateway, nil, "", someGetOrganizationIDResponse)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithRetryPolicy(someRetryPolicy))
+
+	// when
+	_, err := client.GetOrganizationID(context.TODO(), "some organization")
+
+	// then
+	var target ErrorWithStatusCode
+	assert.ErrorAs(t, err, &target)
+	assert.Equal(t, http.StatusBadGateway, target.StatusCode)
+	assert.Equal(t, int64(4), requests.Load())
+}
+
+func TestClientDoesNotRetryByDefault(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, requests := serverFailingBeforeResponding(1, http.StatusBadGateway, nil, "", someGetOrganizationIDResponse)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	_, err := client.GetOrganizationID(context.TODO(), "some organization")
+
+	// then
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), requests.Load())
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, requests := serverFailingBeforeResponding(1, http.StatusForbidden, nil, `{"message": "Resource not accessible by integration"}`, someGetOrganizationIDResponse)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithRetryPolicy(someRetryPolicy))
+
+	// when
+	_, err := client.GetOrganizationID(context.TODO(), "some organization")
+
+	// then
+	assert.ErrorContains(t, err, "Resource not accessible by integration")
+	assert.Equal(t, int64(1), requests.Load())
+}
+
+func TestClientRetriesAfterRequestedWait(t *testing.T) {
+	// given
+	headers := map[string]string{"Retry-After": "0"}
+	gitHubGraphQLAPIMock, requests := serverFailingBeforeResponding(1, http.StatusForbidden, headers, secondaryRateLimitResponse, someGetOrganizationIDResponse)
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithRetryPolicy(someRetryPolicy))
+
+	// when
+	_, err := client.GetOrganizationID(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), requests.Load())
+}
+
+func TestClientDoesNotRetryMutationsWhichReachedGitHub(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock, requests := serverFailingBeforeResponding(1, http.StatusBadGateway, nil, "", createEntryResponseWith(someCreatedEntry))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithRetryPolicy(someRetryPolicy))
+
+	// when
+	_, err := client.CreateIPAllowListEntry(context.TODO(), "some owner", someCreatedEntry.Name, someCreatedEntry.AllowListValue, someCreatedEntry.IsActive)
+
+	// then
+	var target ErrorWithStatusCode
+	assert.ErrorAs(t, err, &target)
+	assert.Equal(t, http.StatusBadGateway, target.StatusCode)
+	assert.Equal(t, int64(1), requests.Load())
+}
+
+func TestClientRetriesRateLimitedMutations(t *testing.T) {
+	// given
+	headers := map[string]string{"Retry-After": "0"}
+	gitHubGraphQLAPIMock, requests := serverFailingBeforeResponding(1, http.StatusForbidden, headers, secondaryRateLimitResponse, createEntryResponseWith(someCreatedEntry))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithRetryPolicy(someRetryPolicy))
+
+	// when
+	entry, err := client.CreateIPAllowListEntry(context.TODO(), "some owner", someCreatedEntry.Name, someCreatedEntry.AllowListValue, someCreatedEntry.IsActive)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, &someCreatedEntry, entry)
+	assert.Equal(t, int64(2), requests.Load())
+}
+
+func TestRetryPolicyRetryWaitOfMutations(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, MinBackoff: time.Second, MaxBackoff: 2 * time.Minute}
+	tests := []struct {
+		name          string
+		statusCode    int
+		headers       map[string]string
+		body          string
+		err           error
+		expectedRetry bool
+	}{
+		{name: "connection refused", err: &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, expectedRetry: true},
+		{name: "connection reset", err: &url.Error{Op: "Post", Err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}}},
+		{name: "unexpected EOF", err: &url.Error{Op: "Post", Err: io.ErrUnexpectedEOF}},
+		{name: "server error", statusCode: http.StatusBadGateway},
+		{name: "retry after", statusCode: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "0"}, expectedRetry: true},
+		{name: "secondary rate limit", statusCode: http.StatusForbidden, headers: map[string]string{"Retry-After": "0"}, body: secondaryRateLimitResponse, expectedRetry: true},
+		{name: "rate limit reset", statusCode: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "0"}, expectedRetry: true},
+		{name: "server error with retry after", statusCode: http.StatusServiceUnavailable, headers: map[string]string{"Retry-After": "0"}},
+		{name: "forbidden", statusCode: http.StatusForbidden, body: `{"message": "Must have admin rights to Repository."}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			var res *http.Response
+			if test.err == nil {
+				res = &http.Response{StatusCode: test.statusCode, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(test.body))}
+				for k, v := range test.headers {
+					res.Header.Set(k, v)
+				}
+			}
+
+			// when
+			_, retry := policy.retryWait(context.TODO(), 0, false, res, test.err, time.Now())
+
+			// then
+			assert.Equal(t, test.expectedRetry, retry)
+		})
+	}
+}
+
+func TestClientDoesNotRetrySuccessfulResponsesWithRequestedWait(t *testing.T) {
+	// given
+	var requests atomic.Int64
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(someGetOrganizationIDResponse))
+	}))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithRetryPolicy(someRetryPolicy))
+
+	// when
+	id, err := client.GetOrganizationID(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.NotEmpty(t, id)
+	assert.Equal(t, int64(1), requests.Load())
+}
+
+func TestClientSendsTheSameBodyWhenRetrying(t *testing.T) {
+	// given
+	var bodies []string
+	var requests atomic.Int64
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(someGetOrganizationIDResponse))
+	}))
+	client := NewAuthenticatedGitHubClient(context.TODO(), "", WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL), WithRetryPolicy(someRetryPolicy))
+
+	// when
+	_, err := client.GetOrganizationID(context.TODO(), "some organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, bodies, 2)
+	assert.NotEmpty(t, bodies[0])
+	assert.Equal(t, bodies[0], bodies[1])
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   RetryPolicy
+		expected RetryPolicy
+	}{
+		{name: "max backoff", policy: someRetryPolicy, expected: someRetryPolicy},
+		{name: "default max backoff", policy: RetryPolicy{MaxRetries: 3, MinBackoff: time.Second}, expected: RetryPolicy{MaxRetries: 3, MinBackoff: time.Second, MaxBackoff: defaultMaxBackoff}},
+		{name: "default max backoff shorter than min backoff", policy: RetryPolicy{MaxRetries: 3, MinBackoff: 2 * time.Minute}, expected: RetryPolicy{MaxRetries: 3, MinBackoff: 2 * time.Minute, MaxBackoff: 2 * time.Minute}},
+		{name: "max backoff shorter than min backoff", policy: RetryPolicy{MaxRetries: 3, MinBackoff: time.Minute, MaxBackoff: time.Second}},
+		{name: "negative max retries", policy: RetryPolicy{MaxRetries: -1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			options := &ClientOptions{}
+
+			// when
+			WithRetryPolicy(test.policy)(options)
+
+			// then
+			assert.Equal(t, test.expected, options.retryPolicy)
+		})
+	}
+}
+
+func TestRetryPolicyRetryWait(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	policy := RetryPolicy{MaxRetries: 3, MinBackoff: time.Second, MaxBackoff: 2 * time.Minute}
+	tests := []struct {
+		name          string
+		attempt       int
+		statusCode    int
+		headers       map[string]string
+		body          string
+		expectedRetry bool
+		expectedMin   time.Duration
+		expectedMax   time.Duration
+	}{
+		{name: "server error", statusCode: http.StatusBadGateway, expectedRetry: true, expectedMin: 500 * time.Millisecond, expectedMax: time.Second},
+		{name: "server error backoff grows", attempt: 2, statusCode: http.StatusBadGateway, expectedRetry: true, expectedMin: 2 * time.Second, expectedMax: 4 * time.Second},
+		{name: "max retries", attempt: 3, statusCode: http.StatusBadGateway},
+		{name: "success", statusCode: http.StatusOK},
+		{name: "not found", statusCode: http.StatusNotFound},
+		{name: "success with retry after", statusCode: http.StatusOK, headers: map[string]string{"Retry-After": "0"}},
+		{name: "success with exhausted rate limit", statusCode: http.StatusOK, headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(now.Unix(), 10)}},
+		{name: "not found with retry after", statusCode: http.StatusNotFound, headers: map[string]string{"Retry-After": "0"}},
+		{name: "retry after seconds", statusCode: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "30"}, expectedRetry: true, expectedMin: 30 * time.Second, expectedMax: 30 * time.Second},
+		{name: "retry after date", statusCode: http.StatusServiceUnavailable, headers: map[string]string{"Retry-After": now.Add(45 * time.Second).Format(http.TimeFormat)}, expectedRetry: true, expectedMin: 45 * time.Second, expectedMax: 45 * time.Second},
+		{name: "retry after longer than max backoff", statusCode: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "3600"}},
+		{name: "rate limit reset", statusCode: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(now.Add(time.Minute).Unix(), 10)}, expectedRetry: true, expectedMin: time.Minute, expectedMax: time.Minute},
+		{name: "secondary rate limit", statusCode: http.StatusForbidden, body: secondaryRateLimitResponse, expectedRetry: true, expectedMin: secondaryRateLimitWait, expectedMax: secondaryRateLimitWait},
+		{name: "forbidden", statusCode: http.StatusForbidden, body: `{"message": "Must have admin rights to Repository."}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			res := &http.Response{StatusCode: test.statusCode, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(test.body))}
+			for k, v := range test.headers {
+				res.Header.Set(k, v)
+			}
+
+			// when
+			wait, retry := policy.retryWait(context.TODO(), test.attempt, true, res, nil, now)
+
+			// then
+			assert.Equal(t, test.expectedRetry, retry)
+			assert.GreaterOrEqual(t, wait, test.expectedMin)
+			assert.LessOrEqual(t, wait, test.expectedMax)
+			body, _ := io.ReadAll(res.Body)
+			assert.Equal(t, test.body, string(body))
+		})
+	}
+}
+
+func serverFailingBeforeResponding(failures int64, statusCode int, headers map[string]string, failureBody string, body string) (*httptest.Server, *atomic.Int64) {
+	var requests atomic.Int64
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			for k, v := range headers {
+				w.Header().Set(k, v)
+			}
+			w.WriteHeader(statusCode)
+			_, _ = w.Write([]byte(failureBody))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(body))
+	}))
+	return gitHubGraphQLAPIMock, &requests
+}
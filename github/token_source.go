@@ -0,0 +1,95 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// tokenRequestTimeout limits how long a request for a token may block GitHub's GraphQL API calls.
+const tokenRequestTimeout = 30 * time.Second
+
+// FileTokenSource is an oauth2.TokenSource reading a token from a file, e.g. a mounted secret.
+// A token is considered expired after refreshInterval, so that a rotated token is picked up.
+type FileTokenSource struct {
+	path            string
+	refreshInterval time.Duration
+	now             func() time.Time
+}
+
+// NewFileTokenSource creates a token source reading a token from a file at path.
+// The file is read again after refreshInterval, or never if refreshInterval is 0.
+func NewFileTokenSource(path string, refreshInterval time.Duration) *FileTokenSource {
+	return &FileTokenSource{
+		path:            path,
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+	}
+}
+
+// Token reads a token from the file. Leading and trailing white space is ignored.
+func (s *FileTokenSource) Token() (*oauth2.Token, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "token file read error")
+	}
+	return tokenExpiringAfter(string(b), s.refreshInterval, s.now, "token file "+s.path)
+}
+
+// CommandTokenSource is an oauth2.TokenSource reading a token from the standard output of a command, e.g. `gh auth token`.
+// A token is considered expired after refreshInterval, so that the command is run again to get a fresh token.
+type CommandTokenSource struct {
+	ctx             context.Context
+	command         []string
+	refreshInterval time.Duration
+	now             func() time.Time
+}
+
+// NewCommandTokenSource creates a token source running a command with a name and arguments given as command.
+// The command is run with ctx, so it has to outlive the token source. It is run again after refreshInterval, or never if refreshInterval is 0.
+func NewCommandTokenSource(ctx context.Context, command []string, refreshInterval time.Duration) (*CommandTokenSource, error) {
+	if len(command) == 0 || command[0] == "" {
+		return nil, errors.New("token command is empty")
+	}
+	return &CommandTokenSource{
+		ctx:             ctx,
+		command:         command,
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+	}, nil
+}
+
+// Token runs the command and reads a token from its standard output. Leading and trailing white space is ignored.
+func (s *CommandTokenSource) Token() (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, tokenRequestTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.command[0], s.command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "token command %q error: %s", strings.Join(s.command, " "), strings.TrimSpace(stderr.String()))
+	}
+	return tokenExpiringAfter(stdout.String(), s.refreshInterval, s.now, "token command "+s.command[0])
+}
+
+func tokenExpiringAfter(token string, refreshInterval time.Duration, now func() time.Time, source string) (*oauth2.Token, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, errors.Errorf("%s returned an empty token", source)
+	}
+
+	t := &oauth2.Token{AccessToken: token, TokenType: "Bearer"}
+	if refreshInterval > 0 {
+		t.Expiry = now().Add(refreshInterval)
+	}
+	return t, nil
+}
@@ -0,0 +1,160 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestNewGitHubClientWithTokenSourceReusesTokenUntilItExpires(t *testing.T) {
+	// given
+	var authorizations []string
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(someGetOrganizationIDResponse))
+	}))
+	tokens := []*oauth2.Token{
+		{AccessToken: "first", Expiry: time.Now().Add(time.Hour)},
+		{AccessToken: "second", Expiry: time.Now().Add(time.Hour)},
+	}
+	tokenSource := &tokenSourceStub{tokens: tokens}
+	client := NewGitHubClientWithTokenSource(context.TODO(), tokenSource, WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))
+
+	// when
+	_, _ = client.GetOrganizationID(context.TODO(), "some organization")
+	tokens[0].Expiry = time.Now()
+	_, _ = client.GetOrganizationID(context.TODO(), "other organization")
+	_, err := client.GetOrganizationID(context.TODO(), "another organization")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bearer first", "Bearer second", "Bearer second"}, authorizations)
+}
+
+func TestFileTokenSource(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(path, []byte("  some token\n"), 0o600))
+	now := time.Now()
+	tokenSource := NewFileTokenSource(path, time.Minute)
+	tokenSource.now = func() time.Time { return now }
+
+	// when
+	token, err := tokenSource.Token()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "some token", token.AccessToken)
+	assert.Equal(t, now.Add(time.Minute), token.Expiry)
+
+	// and when
+	assert.NoError(t, os.WriteFile(path, []byte("rotated token"), 0o600))
+	token, err = tokenSource.Token()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "rotated token", token.AccessToken)
+}
+
+func TestFileTokenSourceWithoutRefreshIntervalNeverExpires(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(path, []byte("some token"), 0o600))
+
+	// when
+	token, err := NewFileTokenSource(path, 0).Token()
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, token.Expiry.IsZero())
+}
+
+func TestFileTokenSourceWithInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	emptyPath := filepath.Join(dir, "empty")
+	assert.NoError(t, os.WriteFile(emptyPath, []byte("\n"), 0o600))
+	tests := []struct {
+		name          string
+		path          string
+		expectedError string
+	}{
+		{name: "missing file", path: filepath.Join(dir, "missing"), expectedError: "token file read error"},
+		{name: "empty file", path: emptyPath, expectedError: "returned an empty token"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// when
+			token, err := NewFileTokenSource(test.path, time.Minute).Token()
+
+			// then
+			assert.ErrorContains(t, err, test.expectedError)
+			assert.Nil(t, token)
+		})
+	}
+}
+
+func TestCommandTokenSource(t *testing.T) {
+	// given
+	tokenSource, err := NewCommandTokenSource(context.TODO(), []string{"echo", "some token"}, time.Minute)
+	assert.NoError(t, err)
+
+	// when
+	token, err := tokenSource.Token()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "some token", token.AccessToken)
+	assert.False(t, token.Expiry.IsZero())
+}
+
+func TestCommandTokenSourceWithFailingCommand(t *testing.T) {
+	tests := []struct {
+		name          string
+		command       []string
+		expectedError string
+	}{
+		{name: "failing command", command: []string{"sh", "-c", "echo some failure >&2; exit 1"}, expectedError: "some failure"},
+		{name: "missing command", command: []string{filepath.Join(t.TempDir(), "missing")}, expectedError: "token command"},
+		{name: "empty output", command: []string{"true"}, expectedError: "returned an empty token"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			tokenSource, err := NewCommandTokenSource(context.TODO(), test.command, time.Minute)
+			assert.NoError(t, err)
+
+			// when
+			token, err := tokenSource.Token()
+
+			// then
+			assert.ErrorContains(t, err, test.expectedError)
+			assert.Nil(t, token)
+		})
+	}
+}
+
+func TestNewCommandTokenSourceWithEmptyCommand(t *testing.T) {
+	// when
+	_, err := NewCommandTokenSource(context.TODO(), nil, time.Minute)
+
+	// then
+	assert.ErrorContains(t, err, "token command is empty")
+}
+
+type tokenSourceStub struct {
+	tokens []*oauth2.Token
+	calls  int
+}
+
+func (s *tokenSourceStub) Token() (*oauth2.Token, error) {
+	token := s.tokens[s.calls]
+	s.calls++
+	return token, nil
+}
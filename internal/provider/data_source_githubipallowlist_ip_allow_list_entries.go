@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/form3tech-oss/terraform-provider-githubipallowlist/github"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	nameRegexKey = "name_regex"
+	containsKey  = "contains"
+	entriesKey   = "entries"
+	idKey        = "id"
+	createdAtKey = "created_at"
+	updatedAtKey = "updated_at"
+
+	inheritedEntriesKey      = "inherited_entries"
+	inheritedEntriesCountKey = "inherited_entries_count"
+)
+
+func dataSourceGitHubIPAllowListEntries() *schema.Resource {
+	return &schema.Resource{
+		Description: "IP allow list entries of the configured owner.",
+
+		ReadContext: dataSourceGitHubIPAllowListEntriesRead,
+
+		Schema: map[string]*schema.Schema{
+			nameRegexKey: {
+				Description:      "A regular expression an entry's name has to match.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+			},
+			isActiveKey: {
+				Description: "Whether to return only active (`true`) or only inactive (`false`) entries. All entries are returned if not set.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+			containsKey: {
+				Description:      "A single IP address or range of IP addresses in CIDR notation an entry has to fully contain.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateAllowListValue,
+			},
+			entriesKey: {
+				Description: "Entries matching all given filters.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        dataSourceIPAllowListEntryResource(),
+			},
+			inheritedEntriesCountKey: {
+				Description: "The number of entries an organization inherits from its enterprise. GitHub lists them without any details, so they are not part of `entries`.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			inheritedEntriesKey: {
+				Description: "Entries of the enterprise an organization inherits entries from, matching all given filters. Resolved only if the provider's `organization_enterprise` is set.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        dataSourceIPAllowListEntryResource(),
+			},
+		},
+	}
+}
+
+// dataSourceIPAllowListEntryResource is the schema of a single entry returned by the data source.
+func dataSourceIPAllowListEntryResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			idKey: {
+				Description: "The GraphQL node ID of the entry.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			nameKey: {
+				Description: "A name of the entry.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			allowListValueKey: {
+				Description: "A single IP address or range of IP addresses in CIDR notation.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			isActiveKey: {
+				Description: "Whether the entry is currently active.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			createdAtKey: {
+				Description: "RFC 3339 timestamp of when the entry was created.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			updatedAtKey: {
+				Description: "RFC 3339 timestamp of when the entry was last updated.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceGitHubIPAllowListEntriesRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	client := meta.(*apiClient)
+
+	filter, err := entriesFilterFrom(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	owner, err := client.resolveOwner("", "", "")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	entries, err := owner.entries(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = d.Set(entriesKey, filter.matchingEntries(entries))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(owner.Name)
+
+	// Like the ip_allow_list resource, entries inherited from the enterprise which cannot be listed, e.g. without access to it, are only warned about.
+	inherited, err := owner.inheritedEntries(ctx)
+	if err != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Entries inherited from the enterprise could not be listed",
+			Detail:   err.Error(),
+		}}
+	}
+	err = d.Set(inheritedEntriesCountKey, inherited.Count)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set(inheritedEntriesKey, filter.matchingEntries(inherited.Entries))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+type entriesFilter struct {
+	nameRegex *regexp.Regexp
+	isActive  *bool
+	contains  *github.CIDR
+}
+
+func entriesFilterFrom(d *schema.ResourceData) (entriesFilter, error) {
+	var filter entriesFilter
+
+	if nameRegex, ok := d.GetOk(nameRegexKey); ok {
+		re, err := regexp.Compile(nameRegex.(string))
+		if err != nil {
+			return filter, err
+		}
+		filter.nameRegex = re
+	}
+
+	if !d.GetRawConfig().GetAttr(isActiveKey).IsNull() {
+		isActive := d.Get(isActiveKey).(bool)
+		filter.isActive = &isActive
+	}
+
+	if contains, ok := d.GetOk(containsKey); ok {
+		cidr := github.CIDR(contains.(string))
+		filter.contains = &cidr
+	}
+
+	return filter, nil
+}
+
+func (f entriesFilter) matches(e *github.IPAllowListEntry) bool {
+	if f.nameRegex != nil && !f.nameRegex.MatchString(e.Name) {
+		return false
+	}
+	if f.isActive != nil && *f.isActive != e.IsActive {
+		return false
+	}
+	if f.contains != nil && !e.AllowListValue.Contains(*f.contains) {
+		return false
+	}
+	return true
+}
+
+// matchingEntries returns attributes of entries matching the filter, skipping nil entries.
+func (f entriesFilter) matchingEntries(entries []*github.IPAllowListEntry) []any {
+	matching := make([]any, 0, len(entries))
+	for _, e := range entries {
+		if e == nil || !f.matches(e) {
+			continue
+		}
+		matching = append(matching, map[string]any{
+			idKey:             e.ID,
+			nameKey:           e.Name,
+			allowListValueKey: string(e.AllowListValue),
+			isActiveKey:       e.IsActive,
+			createdAtKey:      e.CreatedAt.Format(time.RFC3339),
+			updatedAtKey:      e.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	return matching
+}
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/form3tech-oss/terraform-provider-githubipallowlist/github"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccDataSourceIPAllowListEntries(t *testing.T) {
+	t.Skip("Acceptance tests are supposed to reach out to a real API. Tests is skipped until we create a test GitHub organisation.")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceIPAllowListEntries,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.githubipallowlist_ip_allow_list_entries.example", "entries.#", "1"),
+					resource.TestCheckResourceAttr("data.githubipallowlist_ip_allow_list_entries.example", "entries.0.allow_list_value", "1.2.3.0/24"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceIPAllowListEntries = `
+resource "githubipallowlist_ip_allow_list_entry" "example" {
+  is_active        = true
+  allow_list_value = "1.2.3.0/24"
+  name             = "data source test"
+}
+
+data "githubipallowlist_ip_allow_list_entries" "example" {
+  name_regex = "^data source test$"
+  is_active  = true
+  contains   = "1.2.3.4"
+
+  depends_on = [githubipallowlist_ip_allow_list_entry.example]
+}
+`
+
+func TestEntriesFilterMatches(t *testing.T) {
+	active := true
+	inactive := false
+	prefix := func(value github.CIDR) *github.CIDR {
+		return &value
+	}
+	entry := &github.IPAllowListEntry{ID: "id", Name: "VPN egress", AllowListValue: "10.1.0.0/16", IsActive: true}
+
+	tests := []struct {
+		name     string
+		filter   entriesFilter
+		expected bool
+	}{
+		{name: "no filters", filter: entriesFilter{}, expected: true},
+		{name: "matching name", filter: entriesFilter{nameRegex: regexp.MustCompile("^VPN")}, expected: true},
+		{name: "other name", filter: entriesFilter{nameRegex: regexp.MustCompile("^Office")}, expected: false},
+		{name: "active", filter: entriesFilter{isActive: &active}, expected: true},
+		{name: "inactive", filter: entriesFilter{isActive: &inactive}, expected: false},
+		{name: "contained address", filter: entriesFilter{contains: prefix("10.1.2.3")}, expected: true},
+		{name: "contained range", filter: entriesFilter{contains: prefix("10.1.2.0/24")}, expected: true},
+		{name: "same range", filter: entriesFilter{contains: prefix("10.1.0.0/16")}, expected: true},
+		{name: "wider range", filter: entriesFilter{contains: prefix("10.0.0.0/8")}, expected: false},
+		{name: "other address", filter: entriesFilter{contains: prefix("10.2.0.1")}, expected: false},
+		{name: "other address family", filter: entriesFilter{contains: prefix("::1")}, expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.filter.matches(entry))
+		})
+	}
+}
+
+func TestDataSourceIPAllowListEntriesReadWithInaccessibleEnterprise(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req github.GraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if strings.Contains(req.Query, "GetEnterpriseIpAllowListEntries") {
+			_, _ = w.Write([]byte(`{"data": {"enterprise": null}, "errors": [
+				{"type": "FORBIDDEN", "path": ["enterprise"], "message": "Resource not accessible by integration"}
+			]}`))
+			return
+		}
+		_, _ = w.Write([]byte(organizationEntriesResponse(t, []*github.IPAllowListEntry{nil, {ID: "IALE_abc", AllowListValue: "1.2.3.4/32"}})))
+	}))
+	defer gitHubGraphQLAPIMock.Close()
+	client := &apiClient{
+		github:                 github.NewGitHubClient(http.DefaultClient, github.WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL)),
+		organizationEnterprise: "some enterprise",
+	}
+	client.owner = client.newOwner(github.OrganizationOwner("some organization"))
+	r := dataSourceGitHubIPAllowListEntries()
+	d := r.Data(&terraform.InstanceState{RawConfig: rawConfig(r, map[string]any{})})
+
+	// when
+	diags := dataSourceGitHubIPAllowListEntriesRead(context.TODO(), d, client)
+
+	// then
+	assert.False(t, diags.HasError())
+	assert.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Contains(t, diags[0].Detail, "Resource not accessible by integration")
+	assert.Equal(t, "some organization", d.Id())
+	assert.Len(t, d.Get(entriesKey), 1)
+	assert.Empty(t, d.Get(inheritedEntriesKey))
+}
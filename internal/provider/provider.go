@@ -2,9 +2,16 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/form3tech-oss/terraform-provider-githubipallowlist/github"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/oauth2"
+	"strings"
+	"time"
 )
 
 func init() {
@@ -19,20 +26,82 @@ func New(version string) func() *schema.Provider {
 					Type:        schema.TypeString,
 					Optional:    true,
 					DefaultFunc: schema.EnvDefaultFunc("GITHUB_TOKEN", nil),
-					Description: "Personal Access Token (classic). Defaults to a value of a GITHUB_TOKEN environmental variable.",
+					Description: "Personal Access Token (classic). Ignored if `app_auth`, `token_file` or `token_command` is set. Defaults to a value of a GITHUB_TOKEN environmental variable.",
+				},
+				"token_file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("GITHUB_TOKEN_FILE", ""),
+					Description: "A path to a file holding a token, e.g. a mounted secret. The file is read again after `token_refresh_interval`. Ignored if `app_auth` or `token_command` is set. Defaults to a value of a GITHUB_TOKEN_FILE environmental variable.",
+				},
+				"token_command": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					MinItems:    1,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "A command printing a token to its standard output, given as a name followed by arguments, e.g. `[\"gh\", \"auth\", \"token\"]`. The command is run again after `token_refresh_interval`. Ignored if `app_auth` is set.",
+				},
+				"token_refresh_interval": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          "5m",
+					ValidateDiagFunc: validateDuration,
+					Description:      "How long a token read from `token_file` or `token_command` is used before it is read again, e.g. `1m`. `0s` reads a token only once. Default: `5m`.",
+				},
+				"app_auth": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					MaxItems:    1,
+					Description: "GitHub App installation credentials used instead of `token`. Attributes default to values of GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID and GITHUB_APP_PEM_FILE environmental variables, so an empty `app_auth {}` block is enough when they are set.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"id": {
+								Type:        schema.TypeString,
+								Optional:    true,
+								DefaultFunc: schema.EnvDefaultFunc("GITHUB_APP_ID", nil),
+								Description: "The GitHub App ID or client ID. Defaults to a value of a GITHUB_APP_ID environmental variable.",
+							},
+							"installation_id": {
+								Type:        schema.TypeString,
+								Optional:    true,
+								DefaultFunc: schema.EnvDefaultFunc("GITHUB_APP_INSTALLATION_ID", nil),
+								Description: "The ID of the GitHub App installation on the organization or the enterprise. Defaults to a value of a GITHUB_APP_INSTALLATION_ID environmental variable.",
+							},
+							"pem_file": {
+								Type:        schema.TypeString,
+								Optional:    true,
+								Sensitive:   true,
+								DefaultFunc: schema.EnvDefaultFunc("GITHUB_APP_PEM_FILE", nil),
+								Description: "The contents of the GitHub App's private key PEM file, e.g. `file(\"app.private-key.pem\")`. Defaults to a value of a GITHUB_APP_PEM_FILE environmental variable.",
+							},
+						},
+					},
 				},
 				"organization": {
-					Type:         schema.TypeString,
-					Optional:     true,
-					DefaultFunc:  schema.EnvDefaultFunc("GITHUB_ORGANIZATION", nil),
-					Description:  "The GitHub organization name to manage. Defaults to a value of a GITHUB_ORGANIZATION environmental variable.",
-					ExactlyOneOf: []string{"organization", "enterprise"},
+					Type:          schema.TypeString,
+					Optional:      true,
+					DefaultFunc:   schema.EnvDefaultFunc("GITHUB_ORGANIZATION", nil),
+					Description:   "The GitHub organization name to manage. Can be overridden by resources. Defaults to a value of a GITHUB_ORGANIZATION environmental variable.",
+					ConflictsWith: []string{"enterprise", "app"},
 				},
 				"enterprise": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					DefaultFunc:   schema.EnvDefaultFunc("GITHUB_ENTERPRISE", nil),
+					Description:   "The GitHub enterprise name to manage. Can be overridden by resources. Defaults to a value of a GITHUB_ENTERPRISE environmental variable.",
+					ConflictsWith: []string{"app"},
+				},
+				"app": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("GITHUB_APP_NODE_ID", nil),
+					Description: "The GraphQL node ID of a GitHub App to manage the IP allow list of, e.g. as returned by `gh api /apps/my-app --jq .node_id` for https://github.com/apps/my-app. Can be overridden by resources. GitHub Apps have no IP allow list settings. Defaults to a value of a GITHUB_APP_NODE_ID environmental variable.",
+				},
+				"organization_enterprise": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					DefaultFunc: schema.EnvDefaultFunc("GITHUB_ENTERPRISE", nil),
-					Description: "The GitHub enterprise name to manage. Defaults to a value of a GITHUB_ENTERPRISE environmental variable.",
+					DefaultFunc: schema.EnvDefaultFunc("GITHUB_ORGANIZATION_ENTERPRISE", ""),
+					Description: "The GitHub enterprise name managed organizations belong to. GitHub lists entries an organization inherits from its enterprise without any details, so they are resolved from the enterprise's IP allow list, which requires read access to the enterprise. Inherited entries are only counted if not set. Defaults to a value of a GITHUB_ORGANIZATION_ENTERPRISE environmental variable.",
 				},
 				"base_url": {
 					Type:        schema.TypeString,
@@ -46,9 +115,73 @@ func New(version string) func() *schema.Provider {
 					Default:     1,
 					Description: "Concurrency of the client. Determines maximum number of concurrent requests to the GitHub GraphQL API. Used to control rate limiting. Default: 1.",
 				},
+				"rate_limit_threshold": {
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     0,
+					Description: "Requests are paused until GitHub's GraphQL API rate limit is reset once fewer points than the threshold remain, instead of using up the budget and failing. `0` never pauses requests. Default: 0.",
+				},
+				"max_retries": {
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     3,
+					Description: "Maximum number of retries of a query which failed with a network error, a 5xx or 429 response, or an exceeded rate limit. Mutations are only retried if a connection to GitHub could not be established or GitHub rejected them because of an exceeded rate limit. `0` disables retries. Default: 3.",
+				},
+				"retry_min_backoff": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          "1s",
+					ValidateDiagFunc: validateDuration,
+					Description:      "A wait before the first retry of a request, e.g. `500ms`. It doubles with each following retry, with jitter added. Default: `1s`.",
+				},
+				"retry_max_backoff": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          "1m",
+					ValidateDiagFunc: validateDuration,
+					Description:      "Maximum wait between retries of a request, e.g. `2m`. A wait requested by GitHub with a Retry-After or an X-RateLimit-Reset header is honoured unless it is longer. Default: `1m`.",
+				},
+				"cache_ttl": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					DefaultFunc:      schema.EnvDefaultFunc("GITHUB_IP_ALLOW_LIST_CACHE_TTL", "0s"),
+					ValidateDiagFunc: validateDuration,
+					Description:      "How long listed entries are cached, e.g. `5m`. By default, entries are cached for the lifetime of the provider process. Defaults to a value of a GITHUB_IP_ALLOW_LIST_CACHE_TTL environmental variable.",
+				},
+				"cache_dir": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("GITHUB_IP_ALLOW_LIST_CACHE_DIR", ""),
+					Description: "A directory where listed entries are additionally cached, so that `terraform apply` reuses entries listed by `terraform plan` within `cache_ttl`. Requires `cache_ttl`. Defaults to a value of a GITHUB_IP_ALLOW_LIST_CACHE_DIR environmental variable.",
+				},
+				"refresh_mode": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          refreshModeList,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{refreshModeList, refreshModeNode}, false)),
+					Description:      "How `githubipallowlist_ip_allow_list_entry` resources are refreshed. `list` lists (and caches) all entries of an owner, which suits many resources of the same owner. `node` fetches each entry by its ID, which suits a few resources or disabled caching. Default: `list`.",
+				},
+				"default_name": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "Managed by Terraform",
+					Description: "A name given to entries which do not set a name. Default: `Managed by Terraform`.",
+				},
+				"name_prefix": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "",
+					Description: "A prefix prepended to `default_name` for entries which do not set a name.",
+				},
+			},
+			DataSourcesMap: map[string]*schema.Resource{
+				"githubipallowlist_ip_allow_list_entries": dataSourceGitHubIPAllowListEntries(),
 			},
 			ResourcesMap: map[string]*schema.Resource{
-				"githubipallowlist_ip_allow_list_entry": resourceGitHubIPAllowListEntry(),
+				"githubipallowlist_ip_allow_list_entry":                      resourceGitHubIPAllowListEntry(),
+				"githubipallowlist_ip_allow_list":                            resourceGitHubIPAllowList(),
+				"githubipallowlist_ip_allow_list_setting":                    resourceGitHubIPAllowListSetting(),
+				"githubipallowlist_ip_allow_list_for_installed_apps_setting": resourceGitHubIPAllowListForInstalledAppsSetting(),
 			},
 		}
 
@@ -58,11 +191,182 @@ func New(version string) func() *schema.Provider {
 	}
 }
 
+const (
+	refreshModeList = "list"
+	refreshModeNode = "node"
+)
+
 type apiClient struct {
-	github         *github.Client
-	ownerName      string
-	ownerID        string
-	getEntriesFunc func(context.Context, string) ([]*github.IPAllowListEntry, error)
+	github                 *github.Client
+	owner                  *owner
+	organizationEnterprise string
+	defaultName            string
+	refreshByNode          bool
+}
+
+// owner is an organization, an enterprise or a GitHub App owning IP allow list entries.
+// Its ID and entries are resolved lazily and cached by the GitHub client.
+type owner struct {
+	github.Owner
+	client *apiClient
+}
+
+func (c *apiClient) newOwner(o github.Owner) *owner {
+	return &owner{Owner: o, client: c}
+}
+
+func (o *owner) id(ctx context.Context) (string, error) {
+	return o.client.github.GetOwnerID(ctx, o.Owner)
+}
+
+func (o *owner) entries(ctx context.Context) ([]*github.IPAllowListEntry, error) {
+	return o.client.github.GetOwnerIPAllowListEntries(ctx, o.Owner)
+}
+
+// entryByID returns an owner's entry with a given entryID, or an error matching github.ErrNotFound if there is none.
+func (o *owner) entryByID(ctx context.Context, entryID string) (*github.IPAllowListEntry, error) {
+	return o.client.github.GetOwnerIPAllowListEntryByID(ctx, o.Owner, entryID)
+}
+
+// entriesByCIDR returns an owner's entries with a value equal to a given value regardless of its notation.
+func (o *owner) entriesByCIDR(ctx context.Context, value github.CIDR) ([]*github.IPAllowListEntry, error) {
+	return o.client.github.GetOwnerIPAllowListEntriesByCIDR(ctx, o.Owner, value)
+}
+
+// inheritedEntries returns entries the owner inherits from an enterprise, which its entries hold as nil entries.
+// Only organizations inherit entries.
+func (o *owner) inheritedEntries(ctx context.Context) (*github.InheritedIPAllowListEntries, error) {
+	if o.Kind != github.OwnerKindOrganization {
+		return &github.InheritedIPAllowListEntries{}, nil
+	}
+	return o.client.github.GetOrganizationInheritedIPAllowListEntries(ctx, o.Name, o.client.organizationEnterprise)
+}
+
+func (o *owner) enabledSetting(ctx context.Context) (github.IPAllowListEnabledSettingValue, error) {
+	return o.client.github.GetOwnerIPAllowListEnabledSetting(ctx, o.Owner)
+}
+
+func (o *owner) forInstalledAppsEnabledSetting(ctx context.Context) (github.IPAllowListForInstalledAppsEnabledSettingValue, error) {
+	return o.client.github.GetOwnerIPAllowListForInstalledAppsEnabledSetting(ctx, o.Owner)
+}
+
+// resolveOwner returns an owner for a given organization, enterprise or app, falling back to the provider's owner if all are empty.
+func (c *apiClient) resolveOwner(organization string, enterprise string, app string) (*owner, error) {
+	switch {
+	case organization != "":
+		return c.newOwner(github.OrganizationOwner(organization)), nil
+	case enterprise != "":
+		return c.newOwner(github.EnterpriseOwner(enterprise)), nil
+	case app != "":
+		return c.newOwner(github.AppOwner(app)), nil
+	case c.owner != nil:
+		return c.owner, nil
+	default:
+		return nil, errors.New("no owner configured: set organization, enterprise or app on the provider or on the resource")
+	}
+}
+
+// importOwner sets the owner of an imported resource if importID is prefixed with `<key>/<name>` for any of given owner keys,
+// e.g. `organization/<name>`. Returns the rest of importID following the prefix and a slash, or importID itself if it is not prefixed.
+func importOwner(d *schema.ResourceData, importID string, keys ...string) (string, error) {
+	for _, key := range keys {
+		prefix := key + "/"
+		if !strings.HasPrefix(importID, prefix) {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(importID, prefix), "/", 2)
+		if parts[0] == "" {
+			return "", fmt.Errorf("unexpected import ID %q, missing a name following %s", importID, prefix)
+		}
+		err := d.Set(key, parts[0])
+		if err != nil {
+			return "", err
+		}
+		if len(parts) == 1 {
+			return "", nil
+		}
+		return parts[1], nil
+	}
+	return importID, nil
+}
+
+// validateDuration rejects values which cannot be parsed by time.ParseDuration or are negative.
+func validateDuration(v any, path cty.Path) diag.Diagnostics {
+	duration, err := time.ParseDuration(v.(string))
+	if err == nil && duration < 0 {
+		err = fmt.Errorf("%q is negative", v)
+	}
+	if err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid duration",
+			Detail:        err.Error(),
+			AttributePath: path,
+		}}
+	}
+	return nil
+}
+
+// newRetryPolicy creates a github.RetryPolicy from `max_retries`, `retry_min_backoff` and `retry_max_backoff`.
+func newRetryPolicy(d *schema.ResourceData) (github.RetryPolicy, error) {
+	minBackoff, err := time.ParseDuration(d.Get("retry_min_backoff").(string))
+	if err != nil {
+		return github.RetryPolicy{}, err
+	}
+	maxBackoff, err := time.ParseDuration(d.Get("retry_max_backoff").(string))
+	if err != nil {
+		return github.RetryPolicy{}, err
+	}
+	if minBackoff > maxBackoff {
+		return github.RetryPolicy{}, fmt.Errorf("retry_min_backoff %s is longer than retry_max_backoff %s", minBackoff, maxBackoff)
+	}
+	return github.RetryPolicy{
+		MaxRetries: d.Get("max_retries").(int),
+		MinBackoff: minBackoff,
+		MaxBackoff: maxBackoff,
+	}, nil
+}
+
+// newAppAuthenticatedGitHubClient creates a client authenticated as a GitHub App installation configured in an `app_auth` block.
+// The block is nil if it is empty.
+func newAppAuthenticatedGitHubClient(ctx context.Context, appAuth any, opts []github.ClientOption) (*github.Client, error) {
+	attributes, _ := appAuth.(map[string]any)
+	appID, _ := attributes["id"].(string)
+	installationID, _ := attributes["installation_id"].(string)
+	pemFile, _ := attributes["pem_file"].(string)
+	// Environmental variables in CI systems often hold a PEM file in one line with escaped line breaks.
+	pemFile = strings.ReplaceAll(pemFile, `\n`, "\n")
+
+	client, err := github.NewAppAuthenticatedGitHubClient(ctx, appID, installationID, []byte(pemFile), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("app_auth: %w", err)
+	}
+	return client, nil
+}
+
+// newTokenSource creates a token source reading a token from `token_file` or `token_command`.
+// Returns nil if neither is set.
+func newTokenSource(ctx context.Context, d *schema.ResourceData) (oauth2.TokenSource, error) {
+	refreshInterval, err := time.ParseDuration(d.Get("token_refresh_interval").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	if command, ok := d.Get("token_command").([]any); ok && len(command) > 0 {
+		args := make([]string, len(command))
+		for i, arg := range command {
+			args[i], _ = arg.(string)
+		}
+		tokenSource, err := github.NewCommandTokenSource(ctx, args, refreshInterval)
+		if err != nil {
+			return nil, fmt.Errorf("token_command: %w", err)
+		}
+		return tokenSource, nil
+	}
+	if path := d.Get("token_file").(string); path != "" {
+		return github.NewFileTokenSource(path, refreshInterval), nil
+	}
+	return nil, nil
 }
 
 func configure(version string, p *schema.Provider) func(context.Context, *schema.ResourceData) (any, diag.Diagnostics) {
@@ -72,47 +376,78 @@ func configure(version string, p *schema.Provider) func(context.Context, *schema
 		concurrency := d.Get("concurrency").(int)
 		organization := d.Get("organization").(string)
 		enterprise := d.Get("enterprise").(string)
+		app := d.Get("app").(string)
+		defaultName := d.Get("name_prefix").(string) + d.Get("default_name").(string)
+		cacheTTL, err := time.ParseDuration(d.Get("cache_ttl").(string))
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		cacheDir := d.Get("cache_dir").(string)
+		var diags diag.Diagnostics
+		if cacheDir != "" && cacheTTL == 0 {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "cache_dir has no effect without cache_ttl",
+				Detail:   fmt.Sprintf("Entries are only cached in %s within cache_ttl. Set a non-zero cache_ttl to reuse entries between Terraform runs.", cacheDir),
+			})
+		}
+		retryPolicy, err := newRetryPolicy(d)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
 
 		userAgent := p.UserAgent("terraform-provider-githubipallowlist", version)
 
-		ghc := github.NewAuthenticatedGitHubClient(ctx, token,
+		opts := []github.ClientOption{
 			github.WithGraphQLAPIURL(baseURL),
 			github.WithConcurrency(int64(concurrency)),
 			github.WithHeaders(map[string]string{"User-Agent": userAgent}),
-		)
+			github.WithEntriesCacheTTL(cacheTTL),
+			github.WithEntriesDiskCache(cacheDir),
+			github.WithRetryPolicy(retryPolicy),
+			github.WithRateLimitThreshold(d.Get("rate_limit_threshold").(int)),
+		}
 
-		var ownerID string
-		var ownerName string
-		var getEntriesFunc func(context.Context, string) ([]*github.IPAllowListEntry, error)
-		if organization != "" {
-			id, err := ghc.GetOrganizationID(ctx, organization)
+		// Tokens are requested after configuration, when a context scoped to the configure request is already cancelled.
+		tokenCtx, ok := schema.StopContext(ctx)
+		if !ok {
+			tokenCtx = ctx
+		}
 
+		var ghc *github.Client
+		if appAuth, ok := d.Get("app_auth").([]any); ok && len(appAuth) > 0 {
+			ghc, err = newAppAuthenticatedGitHubClient(tokenCtx, appAuth[0], opts)
 			if err != nil {
 				return nil, diag.FromErr(err)
 			}
-
-			ownerID = id
-			ownerName = organization
-			getEntriesFunc = ghc.GetOrganizationIPAllowListEntries
-
-		}
-		if enterprise != "" {
-			id, err := ghc.GetEnterpriseID(ctx, enterprise)
-
+		} else {
+			tokenSource, err := newTokenSource(tokenCtx, d)
 			if err != nil {
 				return nil, diag.FromErr(err)
 			}
+			if tokenSource != nil {
+				ghc = github.NewGitHubClientWithTokenSource(tokenCtx, tokenSource, opts...)
+			} else {
+				ghc = github.NewAuthenticatedGitHubClient(tokenCtx, token, opts...)
+			}
+		}
 
-			ownerID = id
-			ownerName = enterprise
-			getEntriesFunc = ghc.GetEnterpriseIPAllowListEntries
+		client := &apiClient{
+			github:                 ghc,
+			organizationEnterprise: d.Get("organization_enterprise").(string),
+			defaultName:            defaultName,
+			refreshByNode:          d.Get("refresh_mode").(string) == refreshModeNode,
+		}
+		if organization != "" {
+			client.owner = client.newOwner(github.OrganizationOwner(organization))
+		}
+		if enterprise != "" {
+			client.owner = client.newOwner(github.EnterpriseOwner(enterprise))
+		}
+		if app != "" {
+			client.owner = client.newOwner(github.AppOwner(app))
 		}
 
-		return &apiClient{
-			github:         ghc,
-			ownerName:      ownerName,
-			ownerID:        ownerID,
-			getEntriesFunc: getEntriesFunc,
-		}, nil
+		return client, diags
 	}
 }
@@ -1,8 +1,15 @@
 package provider
 
 import (
+	"context"
+	"github.com/form3tech-oss/terraform-provider-githubipallowlist/github"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 // providerFactories are used to instantiate a provider during acceptance testing.
@@ -22,6 +29,175 @@ func TestProvider(t *testing.T) {
 	}
 }
 
+func TestResolveOwner(t *testing.T) {
+	providerOwner := &owner{Owner: github.OrganizationOwner("provider organization")}
+	client := &apiClient{owner: providerOwner}
+
+	tests := []struct {
+		name         string
+		client       *apiClient
+		organization string
+		enterprise   string
+		app          string
+		expectedName string
+		expectError  bool
+	}{
+		{name: "provider owner", client: client, expectedName: "provider organization"},
+		{name: "organization override", client: client, organization: "some organization", expectedName: "some organization"},
+		{name: "enterprise override", client: client, enterprise: "some enterprise", expectedName: "some enterprise"},
+		{name: "app override", client: client, app: "A_abc", expectedName: "A_abc"},
+		{name: "no owner", client: &apiClient{}, expectError: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// when
+			o, err := test.client.resolveOwner(test.organization, test.enterprise, test.app)
+
+			// then
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedName, o.Name)
+		})
+	}
+}
+
+func TestValidateDuration(t *testing.T) {
+	tests := []struct {
+		value       string
+		expectError bool
+	}{
+		{value: "0s"},
+		{value: "5m"},
+		{value: "1h30m"},
+		{value: "-5m", expectError: true},
+		{value: "5", expectError: true},
+		{value: "five minutes", expectError: true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			// when
+			diags := validateDuration(test.value, cty.GetAttrPath("cache_ttl"))
+
+			// then
+			assert.Equal(t, test.expectError, diags.HasError())
+		})
+	}
+}
+
+func TestNewRetryPolicy(t *testing.T) {
+	// given
+	d := schema.TestResourceDataRaw(t, New("dev")().Schema, map[string]any{"max_retries": 5, "retry_min_backoff": "500ms"})
+
+	// when
+	policy, err := newRetryPolicy(d)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, github.RetryPolicy{MaxRetries: 5, MinBackoff: 500 * time.Millisecond, MaxBackoff: time.Minute}, policy)
+}
+
+func TestNewRetryPolicyWithMinBackoffLongerThanMaxBackoff(t *testing.T) {
+	// given
+	d := schema.TestResourceDataRaw(t, New("dev")().Schema, map[string]any{"retry_min_backoff": "2m", "retry_max_backoff": "1m"})
+
+	// when
+	_, err := newRetryPolicy(d)
+
+	// then
+	assert.EqualError(t, err, "retry_min_backoff 2m0s is longer than retry_max_backoff 1m0s")
+}
+
+func TestNewAppAuthenticatedGitHubClient(t *testing.T) {
+	tests := []struct {
+		name          string
+		appAuth       any
+		expectedError string
+	}{
+		{name: "empty block", appAuth: nil, expectedError: "app_auth: GitHub App ID is empty"},
+		{name: "missing installation ID", appAuth: map[string]any{"id": "123"}, expectedError: "app_auth: GitHub App installation ID is empty"},
+		{name: "invalid private key", appAuth: map[string]any{"id": "123", "installation_id": "456", "pem_file": "some key"}, expectedError: "app_auth: GitHub App private key is not PEM encoded"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// when
+			_, err := newAppAuthenticatedGitHubClient(context.TODO(), test.appAuth, nil)
+
+			// then
+			assert.EqualError(t, err, test.expectedError)
+		})
+	}
+}
+
+func TestNewTokenSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(path, []byte("file token\n"), 0o600))
+	tests := []struct {
+		name          string
+		config        map[string]any
+		expectedToken string
+	}{
+		{name: "token file", config: map[string]any{"token_file": path}, expectedToken: "file token"},
+		{name: "token command", config: map[string]any{"token_command": []any{"echo", "command token"}}, expectedToken: "command token"},
+		{name: "token command and file", config: map[string]any{"token_file": path, "token_command": []any{"echo", "command token"}}, expectedToken: "command token"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			d := schema.TestResourceDataRaw(t, New("dev")().Schema, test.config)
+
+			// when
+			tokenSource, err := newTokenSource(context.TODO(), d)
+
+			// then
+			assert.NoError(t, err)
+			token, err := tokenSource.Token()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedToken, token.AccessToken)
+		})
+	}
+}
+
+func TestNewTokenSourceWithoutTokenFileOrCommand(t *testing.T) {
+	// given
+	t.Setenv("GITHUB_TOKEN_FILE", "")
+	d := schema.TestResourceDataRaw(t, New("dev")().Schema, map[string]any{"token": "some token"})
+
+	// when
+	tokenSource, err := newTokenSource(context.TODO(), d)
+
+	// then
+	assert.NoError(t, err)
+	assert.Nil(t, tokenSource)
+}
+
+func TestConfigureWarnsOfCacheDirWithoutCacheTTL(t *testing.T) {
+	tests := []struct {
+		name             string
+		cacheTTL         string
+		expectedWarnings int
+	}{
+		{name: "without cache TTL", cacheTTL: "0s", expectedWarnings: 1},
+		{name: "with cache TTL", cacheTTL: "5m", expectedWarnings: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			p := New("dev")()
+			d := schema.TestResourceDataRaw(t, p.Schema, map[string]any{"token": "some token", "cache_dir": t.TempDir(), "cache_ttl": test.cacheTTL})
+
+			// when
+			_, diags := configure("dev", p)(context.TODO(), d)
+
+			// then
+			assert.False(t, diags.HasError())
+			assert.Len(t, diags, test.expectedWarnings)
+		})
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
 	// You can add code here to run prior to any test case execution, for example assertions
 	// about the appropriate environment variables being set are common to see in a pre-check
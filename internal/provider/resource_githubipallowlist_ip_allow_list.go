@@ -0,0 +1,382 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/form3tech-oss/terraform-provider-githubipallowlist/github"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	entryKey     = "entry"
+	exclusiveKey = "exclusive"
+)
+
+func resourceGitHubIPAllowList() *schema.Resource {
+	return &schema.Resource{
+		Description: "Authoritative GitHub IP allow list. Makes the configured owner's IP allow list match the given entries.",
+
+		CreateContext: resourceGitHubIPAllowListCreate,
+		ReadContext:   resourceGitHubIPAllowListRead,
+		UpdateContext: resourceGitHubIPAllowListUpdate,
+		DeleteContext: resourceGitHubIPAllowListDelete,
+
+		CustomizeDiff: resourceGitHubIPAllowListCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			entryKey: {
+				Description: "An IP allow list entry. Entries are matched with existing ones by `allow_list_value`.",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Set:         allowListEntryHash,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						allowListValueKey: {
+							Description:      "A single IP address or range of IP addresses in CIDR notation.",
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateDiagFunc: validateAllowListValue,
+							DiffSuppressFunc: suppressEquivalentAllowListValues,
+						},
+						isActiveKey: {
+							Description: "Whether the entry is currently active.",
+							Type:        schema.TypeBool,
+							Required:    true,
+						},
+						nameKey: {
+							Description: "A name of the entry. Defaults to the provider's `name_prefix` followed by `default_name`.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+					},
+				},
+			},
+			exclusiveKey: {
+				Description: "Whether entries which are not configured are deleted. When `false`, entries which were neither configured nor previously managed by this resource are left untouched. Default: `true`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+		},
+	}
+}
+
+func resourceGitHubIPAllowListCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	client := meta.(*apiClient)
+
+	owner, err := client.resolveOwner("", "", "")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	ownerID, err := owner.id(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = applyIPAllowList(ctx, client, owner, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(ownerID)
+
+	tflog.Trace(ctx, "created a resource githubipallowlist_ip_allow_list", map[string]interface{}{"id": ownerID})
+
+	return inheritedEntriesWarnings(ctx, owner, d)
+}
+
+func resourceGitHubIPAllowListRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	client := meta.(*apiClient)
+
+	owner, err := client.resolveOwner("", "", "")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	entries, err := owner.entries(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	exclusive := d.Get(exclusiveKey).(bool)
+	managed := allowListEntriesByValue(d.Get(entryKey).(*schema.Set))
+
+	current := make([]any, 0, len(entries))
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		previous, ok := managed[github.NormalizedCIDR(e.AllowListValue)]
+		if !exclusive && !ok {
+			continue
+		}
+		name := e.Name
+		if ok && previous.Name == "" && name == client.defaultName {
+			name = ""
+		}
+		current = append(current, map[string]any{
+			allowListValueKey: string(e.AllowListValue),
+			isActiveKey:       e.IsActive,
+			nameKey:           name,
+		})
+	}
+
+	err = d.Set(entryKey, current)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceGitHubIPAllowListUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	client := meta.(*apiClient)
+
+	owner, err := client.resolveOwner("", "", "")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = applyIPAllowList(ctx, client, owner, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Trace(ctx, "updated a resource githubipallowlist_ip_allow_list", map[string]interface{}{"id": d.Id()})
+
+	return inheritedEntriesWarnings(ctx, owner, d)
+}
+
+func resourceGitHubIPAllowListDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	client := meta.(*apiClient)
+
+	owner, err := client.resolveOwner("", "", "")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	entries, err := owner.entries(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	managed := allowListEntriesByValue(d.Get(entryKey).(*schema.Set))
+
+	var deletes []string
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		if _, ok := managed[github.NormalizedCIDR(e.AllowListValue)]; !ok {
+			continue
+		}
+		deletes = append(deletes, e.ID)
+	}
+	err = applyIPAllowListChanges(ctx, client, "", ipAllowListChanges{deletes: deletes})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Trace(ctx, "deleted a resource githubipallowlist_ip_allow_list", map[string]interface{}{"id": d.Id()})
+
+	return nil
+}
+
+// resourceGitHubIPAllowListCustomizeDiff rejects entries sharing a value as entries are matched by value.
+func resourceGitHubIPAllowListCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ any) error {
+	seen := make(map[github.CIDR]bool)
+	for _, raw := range d.Get(entryKey).(*schema.Set).List() {
+		value := github.CIDR(raw.(map[string]any)[allowListValueKey].(string))
+		if seen[github.NormalizedCIDR(value)] {
+			return fmt.Errorf("allow_list_value %q is configured more than once", value)
+		}
+		seen[github.NormalizedCIDR(value)] = true
+	}
+	return nil
+}
+
+// inheritedEntriesWarnings warns about configured entries which are already covered by active entries the owner inherits from its enterprise,
+// or that inherited entries exist but could not be checked as they were not resolved.
+func inheritedEntriesWarnings(ctx context.Context, owner *owner, d *schema.ResourceData) diag.Diagnostics {
+	configured := d.Get(entryKey).(*schema.Set)
+	if configured.Len() == 0 {
+		return nil
+	}
+
+	inherited, err := owner.inheritedEntries(ctx)
+	if err != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Entries inherited from the enterprise could not be checked",
+			Detail:   err.Error(),
+		}}
+	}
+	return inheritedEntriesCoverageWarnings(inherited, configured)
+}
+
+// inheritedEntriesCoverageWarnings warns about configured entries which are already covered by active inherited entries,
+// or that inherited entries exist but could not be checked as they were not resolved.
+func inheritedEntriesCoverageWarnings(inherited *github.InheritedIPAllowListEntries, configured *schema.Set) diag.Diagnostics {
+	if inherited.Count == 0 {
+		return nil
+	}
+	if inherited.Entries == nil {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Entries inherited from the enterprise could not be checked",
+			Detail:   fmt.Sprintf("The organization inherits %d entries from its enterprise, which may already cover configured entries. Set the provider's organization_enterprise to check them.", inherited.Count),
+		}}
+	}
+
+	var diags diag.Diagnostics
+	for _, raw := range configured.List() {
+		value := github.CIDR(raw.(map[string]any)[allowListValueKey].(string))
+		for _, e := range inherited.Entries {
+			if e == nil || !e.IsActive || !e.AllowListValue.Contains(value) {
+				continue
+			}
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Entry already covered by an inherited entry",
+				Detail:   fmt.Sprintf("allow_list_value %q is already covered by %q (%s) of the enterprise %s.", value, e.AllowListValue, e.Name, inherited.Enterprise),
+			})
+			break
+		}
+	}
+	return diags
+}
+
+// allowListEntryHash hashes an entry using its normalized value, so different notations of the same range are the same entry.
+func allowListEntryHash(v any) int {
+	entry := v.(map[string]any)
+	value := github.NormalizedCIDR(github.CIDR(entry[allowListValueKey].(string)))
+	return schema.HashString(fmt.Sprintf("%s-%s-%t", value, entry[nameKey], entry[isActiveKey]))
+}
+
+// ipAllowListChanges holds mutations required to make an owner's IP allow list match the desired entries.
+type ipAllowListChanges struct {
+	creates []github.IPAllowListEntryParameters
+	updates map[string]github.IPAllowListEntryParameters
+	deletes []string
+}
+
+// applyIPAllowList computes changes between the configured entries and the owner's current entries and executes them.
+func applyIPAllowList(ctx context.Context, client *apiClient, owner *owner, d *schema.ResourceData) error {
+	ownerID, err := owner.id(ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := owner.entries(ctx)
+	if err != nil {
+		return err
+	}
+
+	oldEntries, newEntries := d.GetChange(entryKey)
+	desired := allowListEntriesByValue(newEntries.(*schema.Set))
+	for value, params := range desired {
+		if params.Name == "" {
+			params.Name = client.defaultName
+			desired[value] = params
+		}
+	}
+
+	var owned map[github.CIDR]github.IPAllowListEntryParameters
+	if !d.Get(exclusiveKey).(bool) {
+		owned = allowListEntriesByValue(oldEntries.(*schema.Set))
+	}
+
+	changes, err := computeIPAllowListChanges(desired, owned, entries)
+	if err != nil {
+		return err
+	}
+
+	tflog.Debug(ctx, "applying githubipallowlist_ip_allow_list changes", map[string]interface{}{
+		"creates": len(changes.creates),
+		"updates": len(changes.updates),
+		"deletes": len(changes.deletes),
+	})
+
+	return applyIPAllowListChanges(ctx, client, ownerID, changes)
+}
+
+// applyIPAllowListChanges executes changes in batches. Entries which are already deleted are not an error.
+func applyIPAllowListChanges(ctx context.Context, client *apiClient, ownerID string, changes ipAllowListChanges) error {
+	result, err := client.github.ApplyIPAllowListChanges(ctx, ownerID, changes.creates, changes.updates, changes.deletes)
+	if err == nil {
+		return nil
+	}
+
+	var errs error
+	for _, id := range changes.deletes {
+		r := result.Deletes[id]
+		if r.Err != nil && !errors.Is(r.Err, github.ErrNotFound) {
+			errs = multierror.Append(errs, r.Err)
+		}
+	}
+	for _, r := range result.Updates {
+		if r.Err != nil {
+			errs = multierror.Append(errs, r.Err)
+		}
+	}
+	for _, r := range result.Creates {
+		if r.Err != nil {
+			errs = multierror.Append(errs, r.Err)
+		}
+	}
+	return errs
+}
+
+// computeIPAllowListChanges matches current entries with desired ones by normalized value.
+// Current entries which are not desired are deleted only if owned is nil (all entries are owned) or holds their value.
+// The first current entry matching a desired value is updated if needed, any other entry with the same value is deleted.
+func computeIPAllowListChanges(desired map[github.CIDR]github.IPAllowListEntryParameters, owned map[github.CIDR]github.IPAllowListEntryParameters, current []*github.IPAllowListEntry) (ipAllowListChanges, error) {
+	values := make([]github.CIDR, 0, len(desired))
+	for value := range desired {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	desiredEntries := make([]github.IPAllowListEntryParameters, 0, len(desired))
+	for _, value := range values {
+		desiredEntries = append(desiredEntries, desired[value])
+	}
+
+	var opts []github.PlanOption
+	if owned != nil {
+		opts = append(opts, github.WithOwnedEntries(func(e *github.IPAllowListEntry) bool {
+			value := github.NormalizedCIDR(e.AllowListValue)
+			_, isOwned := owned[value]
+			_, isDesired := desired[value]
+			return isOwned || isDesired
+		}))
+	}
+	plan, err := github.PlanIPAllowListChanges(desiredEntries, current, opts...)
+	if err != nil {
+		return ipAllowListChanges{}, err
+	}
+
+	return ipAllowListChanges{
+		creates: plan.Creates,
+		updates: plan.UpdatesByID(),
+		deletes: plan.DeletedIDs(),
+	}, nil
+}
+
+// allowListEntriesByValue returns entries keyed by their normalized value.
+func allowListEntriesByValue(entries *schema.Set) map[github.CIDR]github.IPAllowListEntryParameters {
+	byValue := make(map[github.CIDR]github.IPAllowListEntryParameters, entries.Len())
+	for _, raw := range entries.List() {
+		entry := raw.(map[string]any)
+		value := github.CIDR(entry[allowListValueKey].(string))
+		byValue[github.NormalizedCIDR(value)] = github.IPAllowListEntryParameters{
+			Name:     entry[nameKey].(string),
+			Value:    value,
+			IsActive: entry[isActiveKey].(bool),
+		}
+	}
+	return byValue
+}
@@ -2,18 +2,26 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/form3tech-oss/terraform-provider-githubipallowlist/github"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 const (
-	entryDescription  = "Managed by Terraform"
 	isActiveKey       = "is_active"
 	allowListValueKey = "allow_list_value"
+	nameKey           = "name"
+	ipVersionKey      = "ip_version"
+	organizationKey   = "organization"
+	enterpriseKey     = "enterprise"
+	appKey            = "app"
 )
 
 func resourceGitHubIPAllowListEntry() *schema.Resource {
@@ -25,6 +33,12 @@ func resourceGitHubIPAllowListEntry() *schema.Resource {
 		UpdateContext: resourceGitHubIPAllowListEntryUpdate,
 		DeleteContext: resourceGitHubIPAllowListEntryDelete,
 
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceGitHubIPAllowListEntryImport,
+		},
+
+		CustomizeDiff: resourceGitHubIPAllowListEntryCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			isActiveKey: {
 				Description: "Whether the entry is currently active.",
@@ -32,9 +46,43 @@ func resourceGitHubIPAllowListEntry() *schema.Resource {
 				Required:    true,
 			},
 			allowListValueKey: {
-				Description: "A single IP address or range of IP addresses in CIDR notation.",
+				Description:      "A single IPv4 or IPv6 address or range of IP addresses in CIDR notation.",
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateAllowListValue,
+				DiffSuppressFunc: suppressEquivalentAllowListValues,
+			},
+			nameKey: {
+				Description: "A name of the entry, e.g. a reason it exists. Defaults to the provider's `name_prefix` followed by `default_name`.",
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
+			},
+			ipVersionKey: {
+				Description: "The IP version of the allow list value, either `4` or `6`.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			organizationKey: {
+				Description:   "The GitHub organization name owning the entry. Overrides the provider's owner.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{enterpriseKey, appKey},
+			},
+			enterpriseKey: {
+				Description:   "The GitHub enterprise name owning the entry. Overrides the provider's owner.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{organizationKey, appKey},
+			},
+			appKey: {
+				Description:   "The GraphQL node ID of a GitHub App owning the entry. Overrides the provider's owner.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{organizationKey, enterpriseKey},
 			},
 		},
 	}
@@ -45,12 +93,17 @@ func resourceGitHubIPAllowListEntryCreate(ctx context.Context, d *schema.Resourc
 
 	isActive := d.Get(isActiveKey).(bool)
 	value := d.Get(allowListValueKey).(string)
+	name := d.Get(nameKey).(string)
 
-	entry, err := client.github.CreateIPAllowListEntry(ctx, client.ownerID, entryDescription, github.CIDR(value), isActive)
+	entry, err := createIPAllowListEntry(ctx, client, d, name, github.CIDR(value), isActive)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	err = setIPVersion(d, entry.AllowListValue)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	d.SetId(entry.ID)
 
 	tflog.Trace(ctx, "created a resource githubipallowlist_ip_allow_list_entry", map[string]interface{}{"id": entry.ID})
@@ -58,16 +111,27 @@ func resourceGitHubIPAllowListEntryCreate(ctx context.Context, d *schema.Resourc
 	return nil
 }
 
+// createIPAllowListEntry creates an entry owned by the resource's owner.
+func createIPAllowListEntry(ctx context.Context, client *apiClient, d *schema.ResourceData, name string, value github.CIDR, isActive bool) (*github.IPAllowListEntry, error) {
+	owner, err := resourceOwner(client, d)
+	if err != nil {
+		return nil, err
+	}
+	ownerID, err := owner.id(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.github.CreateIPAllowListEntry(ctx, ownerID, name, value, isActive)
+}
+
 func resourceGitHubIPAllowListEntryRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	client := meta.(*apiClient)
 
-	entries, err := client.getEntriesFunc(ctx, client.ownerName)
+	id := d.Id()
+	entry, err := readIPAllowListEntry(ctx, client, d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-
-	id := d.Id()
-	entry := firstEntryByID(entries, id)
 	if entry == nil {
 		tflog.Warn(ctx, "githubipallowlist_ip_allow_list_entry not found", map[string]interface{}{"id": id})
 		d.SetId("")
@@ -81,17 +145,77 @@ func resourceGitHubIPAllowListEntryRead(ctx context.Context, d *schema.ResourceD
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	err = d.Set(nameKey, entry.Name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = setIPVersion(d, entry.AllowListValue)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	return nil
 }
 
-func firstEntryByID(entries []*github.IPAllowListEntry, id string) *github.IPAllowListEntry {
-	for _, e := range entries {
-		if e != nil && e.ID == id {
-			return e
+// resourceGitHubIPAllowListEntryCustomizeDiff plans the provider's default name when the name is not configured.
+// It makes entries renamed outside of Terraform show up as a drift even if the name attribute is omitted.
+// It also plans the IP version of a changed allow list value, which is unknown until apply if the value is unknown.
+func resourceGitHubIPAllowListEntryCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta any) error {
+	client := meta.(*apiClient)
+
+	switch {
+	case !d.NewValueKnown(allowListValueKey):
+		// A value coming from another resource may be of either IP version, so the version is only known after apply.
+		err := d.SetNewComputed(ipVersionKey)
+		if err != nil {
+			return err
+		}
+	case d.HasChange(allowListValueKey):
+		version, err := github.CIDR(d.Get(allowListValueKey).(string)).IPVersion()
+		if err == nil && version != d.Get(ipVersionKey).(int) {
+			err = d.SetNew(ipVersionKey, version)
+			if err != nil {
+				return err
+			}
 		}
 	}
-	return nil
+
+	if !d.GetRawConfig().GetAttr(nameKey).IsNull() {
+		return nil
+	}
+	if d.Get(nameKey).(string) == client.defaultName {
+		return nil
+	}
+	return d.SetNew(nameKey, client.defaultName)
+}
+
+// readIPAllowListEntry fetches the entry by its ID or finds it in the owner's entries, depending on the provider's refresh mode.
+// Returns nil if the entry does not exist.
+func readIPAllowListEntry(ctx context.Context, client *apiClient, d *schema.ResourceData) (*github.IPAllowListEntry, error) {
+	if client.refreshByNode {
+		entry, err := client.github.GetIPAllowListEntry(ctx, d.Id())
+		if errors.Is(err, github.ErrNotFound) {
+			return nil, nil
+		}
+		return entry, err
+	}
+
+	owner, err := resourceOwner(client, d)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := owner.entryByID(ctx, d.Id())
+	if errors.Is(err, github.ErrNotFound) {
+		return nil, nil
+	}
+	return entry, err
+}
+
+// resourceOwner returns an owner of the resource, which is either set on the resource or configured for the provider.
+// Resources without an app key, e.g. settings, read an empty app.
+func resourceOwner(client *apiClient, d *schema.ResourceData) (*owner, error) {
+	app, _ := d.Get(appKey).(string)
+	return client.resolveOwner(d.Get(organizationKey).(string), d.Get(enterpriseKey).(string), app)
 }
 
 func resourceGitHubIPAllowListEntryUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
@@ -100,13 +224,19 @@ func resourceGitHubIPAllowListEntryUpdate(ctx context.Context, d *schema.Resourc
 	id := d.Id()
 	isActive := d.Get(isActiveKey).(bool)
 	value := d.Get(allowListValueKey).(string)
+	name := d.Get(nameKey).(string)
 
 	entry, err := client.github.UpdateIPAllowListEntry(ctx, id,
 		github.IPAllowListEntryParameters{
-			Name:     entryDescription,
+			Name:     name,
 			Value:    github.CIDR(value),
 			IsActive: isActive,
 		})
+	if errors.Is(err, github.ErrNotFound) {
+		// Terraform rejects a null state after an update, so the entry deleted outside of Terraform is recreated right away.
+		tflog.Warn(ctx, "githubipallowlist_ip_allow_list_entry to update not found, recreating it", map[string]interface{}{"id": id})
+		entry, err = createIPAllowListEntry(ctx, client, d, name, github.CIDR(value), isActive)
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -119,6 +249,14 @@ func resourceGitHubIPAllowListEntryUpdate(ctx context.Context, d *schema.Resourc
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	err = d.Set(nameKey, entry.Name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = setIPVersion(d, entry.AllowListValue)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	d.SetId(entry.ID)
 
 	tflog.Trace(ctx, "updated a resource githubipallowlist_ip_allow_list_entry", map[string]interface{}{"id": entry.ID})
@@ -130,6 +268,10 @@ func resourceGitHubIPAllowListEntryDelete(ctx context.Context, d *schema.Resourc
 	client := meta.(*apiClient)
 
 	deletedEntryID, err := client.github.DeleteIPAllowListEntry(ctx, d.Id())
+	if errors.Is(err, github.ErrNotFound) {
+		tflog.Warn(ctx, "githubipallowlist_ip_allow_list_entry to delete already deleted", map[string]interface{}{"id": d.Id()})
+		return nil
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -137,3 +279,79 @@ func resourceGitHubIPAllowListEntryDelete(ctx context.Context, d *schema.Resourc
 
 	return nil
 }
+
+// resourceGitHubIPAllowListEntryImport accepts either a GraphQL node ID of an entry or its allow list value.
+// Either can be prefixed with `organization/<name>/`, `enterprise/<name>/` or `app/<node ID>/` to override the provider's owner.
+// An allow list value is resolved to a node ID using the owner's entries.
+func resourceGitHubIPAllowListEntryImport(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	client := meta.(*apiClient)
+
+	importID, err := importOwner(d, d.Id(), organizationKey, enterpriseKey, appKey)
+	if err != nil {
+		return nil, err
+	}
+	if importID == "" {
+		return nil, fmt.Errorf("unexpected import ID %q, expected an ID or a value optionally prefixed with organization/<name>/, enterprise/<name>/ or app/<node ID>/", d.Id())
+	}
+	d.SetId(importID)
+
+	if !isAllowListValue(importID) {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	owner, err := resourceOwner(client, d)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := owner.entriesByCIDR(ctx, github.CIDR(importID))
+	if err != nil {
+		return nil, err
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no IP allow list entry with value %q found for %q", importID, owner.Name)
+	case 1:
+		d.SetId(matches[0].ID)
+		tflog.Trace(ctx, "resolved githubipallowlist_ip_allow_list_entry import", map[string]interface{}{"value": importID, "id": matches[0].ID})
+		return []*schema.ResourceData{d}, nil
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, e := range matches {
+			ids = append(ids, e.ID)
+		}
+		return nil, fmt.Errorf("%d IP allow list entries with value %q found for %q, import one of them by ID instead: %s", len(matches), importID, owner.Name, strings.Join(ids, ", "))
+	}
+}
+
+func isAllowListValue(s string) bool {
+	return github.CIDR(s).Validate() == nil
+}
+
+// validateAllowListValue rejects values which are neither a single IP address nor a range of IP addresses in CIDR notation.
+func validateAllowListValue(v any, path cty.Path) diag.Diagnostics {
+	err := github.CIDR(v.(string)).Validate()
+	if err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid allow list value",
+			Detail:        err.Error(),
+			AttributePath: path,
+		}}
+	}
+	return nil
+}
+
+// suppressEquivalentAllowListValues suppresses a diff between notations of the same range,
+// e.g. 1.2.3.4 and 1.2.3.4/32 or GitHub's canonical 2001:db8::/32 and 2001:DB8::/32.
+func suppressEquivalentAllowListValues(_, old, new string, _ *schema.ResourceData) bool {
+	return github.CIDR(old).Equal(github.CIDR(new))
+}
+
+// setIPVersion sets the IP version of the value. Values GitHub accepts but the provider fails to parse leave it unknown.
+func setIPVersion(d *schema.ResourceData, value github.CIDR) error {
+	version, err := value.IPVersion()
+	if err != nil {
+		return nil
+	}
+	return d.Set(ipVersionKey, version)
+}
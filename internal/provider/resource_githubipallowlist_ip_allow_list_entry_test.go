@@ -1,9 +1,20 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/form3tech-oss/terraform-provider-githubipallowlist/github"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestAccResourceIPAllowListEntry(t *testing.T) {
@@ -18,6 +29,13 @@ func TestAccResourceIPAllowListEntry(t *testing.T) {
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr("githubipallowlist_ip_allow_list_entry.example", "is_active", "false"),
 					resource.TestCheckResourceAttr("githubipallowlist_ip_allow_list_entry.example", "allow_list_value", "1.2.3.4/32"),
+					resource.TestCheckResourceAttr("githubipallowlist_ip_allow_list_entry.example", "name", "Managed by Terraform"),
+				),
+			},
+			{
+				Config: testAccResourceIPAllowListEntryWithName,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("githubipallowlist_ip_allow_list_entry.example", "name", "VPN egress"),
 				),
 			},
 		},
@@ -30,3 +48,336 @@ resource "githubipallowlist_ip_allow_list_entry" "example" {
   allow_list_value = "1.2.3.4/32"
 }
 `
+
+const testAccResourceIPAllowListEntryWithName = `
+resource "githubipallowlist_ip_allow_list_entry" "example" {
+  is_active        = false
+  allow_list_value = "1.2.3.4/32"
+  name             = "VPN egress"
+}
+`
+
+// organizationEntriesResponse returns a response listing given entries of an organization on a single page.
+func organizationEntriesResponse(t *testing.T, entries []*github.IPAllowListEntry) string {
+	nodes, err := json.Marshal(entries)
+	assert.NoError(t, err)
+	return fmt.Sprintf(`{"data": {"organization": {"ipAllowListEntries": {"nodes": %s, "pageInfo": {"hasNextPage": false}}}}}`, nodes)
+}
+
+func TestResourceIPAllowListEntryImport(t *testing.T) {
+	entries := []*github.IPAllowListEntry{
+		nil,
+		{ID: "id-1", AllowListValue: "1.2.3.4/32"},
+		{ID: "id-2", AllowListValue: "10.0.0.0/8"},
+		{ID: "id-3", AllowListValue: "10.0.0.0/8"},
+	}
+	tests := []struct {
+		name                 string
+		importID             string
+		expectedID           string
+		expectedOrganization string
+		expectedEnterprise   string
+		expectedApp          string
+		expectedError        string
+	}{
+		{name: "node ID", importID: "IALE_abc", expectedID: "IALE_abc"},
+		{name: "unique value", importID: "1.2.3.4/32", expectedID: "id-1"},
+		{name: "unknown value", importID: "5.6.7.8", expectedError: "no IP allow list entry"},
+		{name: "ambiguous value", importID: "10.0.0.0/8", expectedError: "id-2, id-3"},
+		{name: "node ID of an organization", importID: "organization/some-org/IALE_abc", expectedID: "IALE_abc", expectedOrganization: "some-org"},
+		{name: "node ID of an enterprise", importID: "enterprise/some-enterprise/IALE_abc", expectedID: "IALE_abc", expectedEnterprise: "some-enterprise"},
+		{name: "node ID of an app", importID: "app/A_abc/IALE_abc", expectedID: "IALE_abc", expectedApp: "A_abc"},
+		{name: "malformed owner", importID: "organization/IALE_abc", expectedError: "unexpected import ID"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(organizationEntriesResponse(t, entries)))
+			}))
+			defer gitHubGraphQLAPIMock.Close()
+			client := &apiClient{github: github.NewGitHubClient(http.DefaultClient, github.WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))}
+			client.owner = client.newOwner(github.OrganizationOwner("some organization"))
+			d := schema.TestResourceDataRaw(t, resourceGitHubIPAllowListEntry().Schema, map[string]any{})
+			d.SetId(test.importID)
+
+			// when
+			imported, err := resourceGitHubIPAllowListEntryImport(context.TODO(), d, client)
+
+			// then
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, imported, 1)
+			assert.Equal(t, test.expectedID, imported[0].Id())
+			assert.Equal(t, test.expectedOrganization, imported[0].Get(organizationKey))
+			assert.Equal(t, test.expectedEnterprise, imported[0].Get(enterpriseKey))
+			assert.Equal(t, test.expectedApp, imported[0].Get(appKey))
+		})
+	}
+}
+
+const responseWithNotFoundError = `{
+    "data": null,
+    "errors": [
+        {
+            "type": "NOT_FOUND",
+            "path": ["deleteIpAllowListEntry"],
+            "message": "Could not resolve to a node with the global id of 'IALE_abc'."
+        }
+    ]
+}`
+
+func TestResourceIPAllowListEntryDeleteOfDeletedEntry(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(responseWithNotFoundError))
+	}))
+	defer gitHubGraphQLAPIMock.Close()
+	client := &apiClient{github: github.NewGitHubClient(http.DefaultClient, github.WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))}
+	d := schema.TestResourceDataRaw(t, resourceGitHubIPAllowListEntry().Schema, map[string]any{})
+	d.SetId("IALE_abc")
+
+	// when
+	diags := resourceGitHubIPAllowListEntryDelete(context.TODO(), d, client)
+
+	// then
+	assert.False(t, diags.HasError())
+}
+
+func TestResourceIPAllowListEntryUpdateOfDeletedEntry(t *testing.T) {
+	// given
+	var createVariables github.Variables
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req github.GraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case strings.Contains(req.Query, "UpdateIpAllowListEntry"):
+			_, _ = w.Write([]byte(responseWithNotFoundError))
+		case strings.Contains(req.Query, "GetOrganizationId"):
+			_, _ = w.Write([]byte(`{"data": {"organization": {"id": "O_abc"}}}`))
+		case strings.Contains(req.Query, "CreateIpAllowListEntry"):
+			createVariables = req.Variables
+			_, _ = w.Write([]byte(`{"data": {"createIpAllowListEntry": {"ipAllowListEntry": {
+				"id": "IALE_def", "allowListValue": "1.2.3.4/32", "name": "some name", "isActive": true,
+				"owner": {"__typename": "Organization", "login": "some organization"}
+			}}}}`))
+		}
+	}))
+	defer gitHubGraphQLAPIMock.Close()
+	client := &apiClient{github: github.NewGitHubClient(http.DefaultClient, github.WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))}
+	d := schema.TestResourceDataRaw(t, resourceGitHubIPAllowListEntry().Schema, map[string]any{isActiveKey: true, allowListValueKey: "1.2.3.4/32", nameKey: "some name", organizationKey: "some organization"})
+	d.SetId("IALE_abc")
+
+	// when
+	diags := resourceGitHubIPAllowListEntryUpdate(context.TODO(), d, client)
+
+	// then
+	assert.False(t, diags.HasError())
+	assert.Equal(t, "IALE_def", d.Id())
+	assert.Equal(t, github.Variables{"ownerId": "O_abc", "name": "some name", "value": "1.2.3.4/32", "isActive": true}, createVariables)
+	assert.Equal(t, 4, d.Get(ipVersionKey))
+}
+
+func TestResourceIPAllowListEntryDeleteWithFailingServer(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer gitHubGraphQLAPIMock.Close()
+	client := &apiClient{github: github.NewGitHubClient(http.DefaultClient, github.WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))}
+	d := schema.TestResourceDataRaw(t, resourceGitHubIPAllowListEntry().Schema, map[string]any{})
+	d.SetId("IALE_abc")
+
+	// when
+	diags := resourceGitHubIPAllowListEntryDelete(context.TODO(), d, client)
+
+	// then
+	assert.True(t, diags.HasError())
+}
+
+func TestResourceIPAllowListEntryReadByNode(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      string
+		expectedID    string
+		expectedValue string
+	}{
+		{name: "existing entry", response: `{"data": {"node": {"id": "IALE_abc", "allowListValue": "1.2.3.4/32", "name": "some name", "isActive": true}}}`, expectedID: "IALE_abc", expectedValue: "1.2.3.4/32"},
+		{name: "deleted entry", response: responseWithNotFoundError, expectedID: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(test.response))
+			}))
+			defer gitHubGraphQLAPIMock.Close()
+			client := &apiClient{
+				github:        github.NewGitHubClient(http.DefaultClient, github.WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL)),
+				refreshByNode: true,
+			}
+			d := schema.TestResourceDataRaw(t, resourceGitHubIPAllowListEntry().Schema, map[string]any{})
+			d.SetId("IALE_abc")
+
+			// when
+			diags := resourceGitHubIPAllowListEntryRead(context.TODO(), d, client)
+
+			// then
+			assert.False(t, diags.HasError())
+			assert.Equal(t, test.expectedID, d.Id())
+			assert.Equal(t, test.expectedValue, d.Get(allowListValueKey))
+		})
+	}
+}
+
+func TestResourceIPAllowListEntryReadOfMissingOrganization(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"organization": null}, "errors": [
+			{"type": "NOT_FOUND", "path": ["organization"], "message": "Could not resolve to an Organization with the login of 'typo'."}
+		]}`))
+	}))
+	defer gitHubGraphQLAPIMock.Close()
+	client := &apiClient{github: github.NewGitHubClient(http.DefaultClient, github.WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))}
+	d := schema.TestResourceDataRaw(t, resourceGitHubIPAllowListEntry().Schema, map[string]any{organizationKey: "typo"})
+	d.SetId("IALE_abc")
+
+	// when
+	diags := resourceGitHubIPAllowListEntryRead(context.TODO(), d, client)
+
+	// then
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "IALE_abc", d.Id())
+}
+
+func TestValidateAllowListValue(t *testing.T) {
+	tests := []struct {
+		value       string
+		expectError bool
+	}{
+		{value: "1.2.3.4"},
+		{value: "1.2.3.4/32"},
+		{value: "10.0.0.0/8"},
+		{value: "10.0.0.0/33", expectError: true},
+		{value: "1.2.3.4/24", expectError: true},
+		{value: "2001:DB8::/32"},
+		{value: "2001:db8::/129", expectError: true},
+		{value: "not an IP address", expectError: true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			// when
+			diags := validateAllowListValue(test.value, cty.GetAttrPath(allowListValueKey))
+
+			// then
+			assert.Equal(t, test.expectError, diags.HasError())
+		})
+	}
+}
+
+func TestSuppressEquivalentAllowListValues(t *testing.T) {
+	assert.True(t, suppressEquivalentAllowListValues(allowListValueKey, "1.2.3.4", "1.2.3.4/32", nil))
+	assert.True(t, suppressEquivalentAllowListValues(allowListValueKey, "10.0.0.0/8", "10.0.0.0/8", nil))
+	assert.False(t, suppressEquivalentAllowListValues(allowListValueKey, "1.2.3.4", "1.2.3.5/32", nil))
+	assert.True(t, suppressEquivalentAllowListValues(allowListValueKey, "2001:db8::/32", "2001:DB8::/32", nil))
+	assert.True(t, suppressEquivalentAllowListValues(allowListValueKey, "2001:db8::1", "2001:0db8:0000::1/128", nil))
+	assert.False(t, suppressEquivalentAllowListValues(allowListValueKey, "", "1.2.3.4/32", nil))
+}
+
+func TestResourceIPAllowListEntryCustomizeDiff(t *testing.T) {
+	client := &apiClient{defaultName: "terraform: Managed by Terraform"}
+	tests := []struct {
+		name         string
+		stateName    string
+		configName   string
+		expectedName string
+	}{
+		{name: "name not configured and renamed outside of Terraform", stateName: "renamed", expectedName: client.defaultName},
+		{name: "name configured", stateName: "renamed", configName: "renamed"},
+		{name: "name not configured and default", stateName: client.defaultName},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			r := resourceGitHubIPAllowListEntry()
+			config := map[string]any{isActiveKey: true, allowListValueKey: "1.2.3.4/32"}
+			if test.configName != "" {
+				config[nameKey] = test.configName
+			}
+			state := &terraform.InstanceState{
+				ID: "IALE_abc",
+				Attributes: map[string]string{
+					"id":              "IALE_abc",
+					isActiveKey:       "true",
+					allowListValueKey: "1.2.3.4/32",
+					nameKey:           test.stateName,
+					ipVersionKey:      "4",
+				},
+				RawConfig: rawConfig(r, config),
+			}
+
+			// when
+			diff, err := r.SimpleDiff(context.TODO(), state, terraform.NewResourceConfigRaw(config), client)
+
+			// then
+			assert.NoError(t, err)
+			if test.expectedName == "" {
+				assert.NotContains(t, diff.Attributes, nameKey)
+				return
+			}
+			assert.Equal(t, test.expectedName, diff.Attributes[nameKey].New)
+		})
+	}
+}
+
+// unknownValue stands for an unknown value in a legacy configuration, like the SDK's internal hcl2shim.UnknownVariableValue.
+const unknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+func TestResourceIPAllowListEntryCustomizeDiffOfUnknownAllowListValue(t *testing.T) {
+	// given
+	r := resourceGitHubIPAllowListEntry()
+	client := &apiClient{defaultName: "terraform: Managed by Terraform"}
+	config := map[string]any{isActiveKey: true, allowListValueKey: unknownValue, nameKey: "some name"}
+	state := &terraform.InstanceState{
+		ID: "IALE_abc",
+		Attributes: map[string]string{
+			"id":              "IALE_abc",
+			isActiveKey:       "true",
+			allowListValueKey: "1.2.3.4/32",
+			nameKey:           "some name",
+			ipVersionKey:      "4",
+		},
+		RawConfig: rawConfig(r, config),
+	}
+
+	// when
+	diff, err := r.SimpleDiff(context.TODO(), state, terraform.NewResourceConfigRaw(config), client)
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, diff.Attributes[ipVersionKey].NewComputed)
+}
+
+// rawConfig returns a configuration of a resource as Terraform sends it, with attributes missing from config set to null.
+func rawConfig(r *schema.Resource, config map[string]any) cty.Value {
+	attrs := map[string]cty.Value{}
+	for name, attrType := range r.CoreConfigSchema().ImpliedType().AttributeTypes() {
+		attrs[name] = cty.NullVal(attrType)
+	}
+	for name, value := range config {
+		switch v := value.(type) {
+		case string:
+			if v == unknownValue {
+				attrs[name] = cty.UnknownVal(cty.String)
+				continue
+			}
+			attrs[name] = cty.StringVal(v)
+		case bool:
+			attrs[name] = cty.BoolVal(v)
+		}
+	}
+	return cty.ObjectVal(attrs)
+}
@@ -0,0 +1,31 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/form3tech-oss/terraform-provider-githubipallowlist/github"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var forInstalledAppsEnabledSetting = ipAllowListSetting{
+	resourceName: "githubipallowlist_ip_allow_list_for_installed_apps_setting",
+	get: func(ctx context.Context, o *owner) (bool, error) {
+		value, err := o.forInstalledAppsEnabledSetting(ctx)
+		return value == github.IPAllowListForInstalledAppsEnabledSettingEnabled, err
+	},
+	set: func(ctx context.Context, client *github.Client, ownerID string, enabled bool) (bool, error) {
+		value := github.IPAllowListForInstalledAppsEnabledSettingDisabled
+		if enabled {
+			value = github.IPAllowListForInstalledAppsEnabledSettingEnabled
+		}
+		value, err := client.UpdateIPAllowListForInstalledAppsEnabledSetting(ctx, ownerID, value)
+		return value == github.IPAllowListForInstalledAppsEnabledSettingEnabled, err
+	},
+}
+
+func resourceGitHubIPAllowListForInstalledAppsSetting() *schema.Resource {
+	return resourceGitHubIPAllowListSettingFor(forInstalledAppsEnabledSetting,
+		"Whether IP allow list configuration of installed GitHub Apps is inherited by an organization or an enterprise.",
+		"Whether IP addresses declared by installed GitHub Apps are added to the IP allow list.")
+}
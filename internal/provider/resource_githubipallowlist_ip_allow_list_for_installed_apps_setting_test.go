@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceIPAllowListForInstalledAppsSetting(t *testing.T) {
+	t.Skip("Acceptance tests are supposed to reach out to a real API. Tests is skipped until we create a test GitHub organisation.")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceIPAllowListForInstalledAppsSetting,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("githubipallowlist_ip_allow_list_for_installed_apps_setting.example", "enabled", "true"),
+				),
+			},
+			{
+				ResourceName:      "githubipallowlist_ip_allow_list_for_installed_apps_setting.example",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+const testAccResourceIPAllowListForInstalledAppsSetting = `
+resource "githubipallowlist_ip_allow_list_for_installed_apps_setting" "example" {
+  enabled = true
+}
+`
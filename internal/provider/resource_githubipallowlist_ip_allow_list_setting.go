@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/form3tech-oss/terraform-provider-githubipallowlist/github"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	enabledKey = "enabled"
+)
+
+// ipAllowListSetting is an owner's setting which is either enabled or disabled.
+type ipAllowListSetting struct {
+	resourceName string
+	get          func(ctx context.Context, o *owner) (bool, error)
+	set          func(ctx context.Context, client *github.Client, ownerID string, enabled bool) (bool, error)
+}
+
+var enabledSetting = ipAllowListSetting{
+	resourceName: "githubipallowlist_ip_allow_list_setting",
+	get: func(ctx context.Context, o *owner) (bool, error) {
+		value, err := o.enabledSetting(ctx)
+		return value == github.IPAllowListEnabledSettingEnabled, err
+	},
+	set: func(ctx context.Context, client *github.Client, ownerID string, enabled bool) (bool, error) {
+		value := github.IPAllowListEnabledSettingDisabled
+		if enabled {
+			value = github.IPAllowListEnabledSettingEnabled
+		}
+		value, err := client.UpdateIPAllowListEnabledSetting(ctx, ownerID, value)
+		return value == github.IPAllowListEnabledSettingEnabled, err
+	},
+}
+
+func resourceGitHubIPAllowListSetting() *schema.Resource {
+	return resourceGitHubIPAllowListSettingFor(enabledSetting,
+		"Whether GitHub IP allow list is enabled for an organization or an enterprise.",
+		"Whether IP allow list is enabled.")
+}
+
+func resourceGitHubIPAllowListSettingFor(setting ipAllowListSetting, description string, enabledDescription string) *schema.Resource {
+	return &schema.Resource{
+		Description: description + " Destroying the resource removes it from the state only and leaves the setting as it is.",
+
+		CreateContext: setting.create,
+		ReadContext:   setting.read,
+		UpdateContext: setting.update,
+		DeleteContext: setting.delete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceGitHubIPAllowListSettingImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			enabledKey: {
+				Description: enabledDescription,
+				Type:        schema.TypeBool,
+				Required:    true,
+			},
+			organizationKey: {
+				Description:   "The GitHub organization name. Overrides the provider's owner.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{enterpriseKey},
+			},
+			enterpriseKey: {
+				Description:   "The GitHub enterprise name. Overrides the provider's owner.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{organizationKey},
+			},
+		},
+	}
+}
+
+func (s ipAllowListSetting) create(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	client := meta.(*apiClient)
+
+	owner, err := resourceOwner(client, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	ownerID, err := owner.id(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	enabled, err := s.set(ctx, client.github, ownerID, d.Get(enabledKey).(bool))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = d.Set(enabledKey, enabled)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(ownerID)
+
+	tflog.Trace(ctx, "created a resource "+s.resourceName, map[string]interface{}{"id": ownerID})
+
+	return nil
+}
+
+func (s ipAllowListSetting) read(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	client := meta.(*apiClient)
+
+	owner, err := resourceOwner(client, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	enabled, err := s.get(ctx, owner)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = d.Set(enabledKey, enabled)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func (s ipAllowListSetting) update(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	client := meta.(*apiClient)
+
+	enabled, err := s.set(ctx, client.github, d.Id(), d.Get(enabledKey).(bool))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = d.Set(enabledKey, enabled)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Trace(ctx, "updated a resource "+s.resourceName, map[string]interface{}{"id": d.Id()})
+
+	return nil
+}
+
+func (s ipAllowListSetting) delete(ctx context.Context, d *schema.ResourceData, _ any) diag.Diagnostics {
+	tflog.Trace(ctx, "deleted a resource "+s.resourceName, map[string]interface{}{"id": d.Id()})
+
+	return nil
+}
+
+// resourceGitHubIPAllowListSettingImport accepts `organization/<name>` or `enterprise/<name>`.
+// Any other import ID has to be the GraphQL node ID of the provider's owner.
+func resourceGitHubIPAllowListSettingImport(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	client := meta.(*apiClient)
+
+	importID, err := importOwner(d, d.Id(), organizationKey, enterpriseKey)
+	if err != nil {
+		return nil, err
+	}
+	if importID != "" && importID != d.Id() {
+		return nil, fmt.Errorf("unexpected import ID %q, expected organization/<name> or enterprise/<name>", d.Id())
+	}
+
+	owner, err := resourceOwner(client, d)
+	if err != nil {
+		return nil, err
+	}
+	ownerID, err := owner.id(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if importID != "" && importID != ownerID {
+		return nil, fmt.Errorf("import ID %q does not match the ID %q of %q", importID, ownerID, owner.Name)
+	}
+	d.SetId(ownerID)
+
+	return []*schema.ResourceData{d}, nil
+}
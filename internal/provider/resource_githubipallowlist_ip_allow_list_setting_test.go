@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/form3tech-oss/terraform-provider-githubipallowlist/github"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccResourceIPAllowListSetting(t *testing.T) {
+	t.Skip("Acceptance tests are supposed to reach out to a real API. Tests is skipped until we create a test GitHub organisation.")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceIPAllowListSetting,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("githubipallowlist_ip_allow_list_setting.example", "enabled", "true"),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceIPAllowListSetting = `
+resource "githubipallowlist_ip_allow_list_setting" "example" {
+  enabled = true
+}
+`
+
+func TestResourceIPAllowListSettingImport(t *testing.T) {
+	tests := []struct {
+		name                 string
+		importID             string
+		expectedID           string
+		expectedOrganization string
+		expectedError        string
+	}{
+		{name: "provider owner ID", importID: "O_provider", expectedID: "O_provider"},
+		{name: "other owner ID", importID: "O_other", expectedError: "does not match"},
+		{name: "organization", importID: "organization/some-org", expectedID: "O_some-org", expectedOrganization: "some-org"},
+		{name: "organization with a suffix", importID: "organization/some-org/abc", expectedError: "unexpected import ID"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req github.GraphQLRequest
+				_ = json.NewDecoder(r.Body).Decode(&req)
+				_, _ = fmt.Fprintf(w, `{"data": {"organization": {"id": "O_%s"}}}`, req.Variables["name"])
+			}))
+			defer gitHubGraphQLAPIMock.Close()
+			client := &apiClient{github: github.NewGitHubClient(http.DefaultClient, github.WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))}
+			client.owner = client.newOwner(github.OrganizationOwner("provider"))
+			d := schema.TestResourceDataRaw(t, resourceGitHubIPAllowListSetting().Schema, map[string]any{})
+			d.SetId(test.importID)
+
+			// when
+			imported, err := resourceGitHubIPAllowListSettingImport(context.TODO(), d, client)
+
+			// then
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, imported, 1)
+			assert.Equal(t, test.expectedID, imported[0].Id())
+			assert.Equal(t, test.expectedOrganization, imported[0].Get(organizationKey))
+		})
+	}
+}
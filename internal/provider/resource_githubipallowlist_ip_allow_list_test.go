@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/form3tech-oss/terraform-provider-githubipallowlist/github"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccResourceIPAllowList(t *testing.T) {
+	t.Skip("Acceptance tests are supposed to reach out to a real API. Tests is skipped until we create a test GitHub organisation.")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceIPAllowList,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("githubipallowlist_ip_allow_list.example", "entry.#", "2"),
+					resource.TestCheckResourceAttr("githubipallowlist_ip_allow_list.example", "exclusive", "false"),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceIPAllowList = `
+resource "githubipallowlist_ip_allow_list" "example" {
+  exclusive = false
+
+  entry {
+    allow_list_value = "1.2.3.4/32"
+    is_active        = true
+    name             = "VPN egress"
+  }
+
+  entry {
+    allow_list_value = "10.0.0.0/8"
+    is_active        = false
+  }
+}
+`
+
+func TestComputeIPAllowListChanges(t *testing.T) {
+	desired := map[github.CIDR]github.IPAllowListEntryParameters{
+		"1.1.1.1/32": {Name: "unchanged", Value: "1.1.1.1/32", IsActive: true},
+		"2.2.2.2/32": {Name: "renamed", Value: "2.2.2.2/32", IsActive: true},
+		"3.3.3.3/32": {Name: "created", Value: "3.3.3.3/32", IsActive: false},
+	}
+	current := []*github.IPAllowListEntry{
+		nil,
+		{ID: "unchanged", AllowListValue: "1.1.1.1", Name: "unchanged", IsActive: true},
+		{ID: "duplicate", AllowListValue: "1.1.1.1/32", Name: "unchanged", IsActive: true},
+		{ID: "renamed", AllowListValue: "2.2.2.2/32", Name: "old name", IsActive: true},
+		{ID: "previously-managed", AllowListValue: "4.4.4.4/32", Name: "removed", IsActive: true},
+		{ID: "unmanaged", AllowListValue: "5.5.5.5/32", Name: "added manually", IsActive: true},
+	}
+
+	t.Run("exclusive", func(t *testing.T) {
+		// when
+		changes, err := computeIPAllowListChanges(desired, nil, current)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, []github.IPAllowListEntryParameters{desired["3.3.3.3/32"]}, changes.creates)
+		assert.Equal(t, map[string]github.IPAllowListEntryParameters{"renamed": desired["2.2.2.2/32"]}, changes.updates)
+		assert.ElementsMatch(t, []string{"duplicate", "previously-managed", "unmanaged"}, changes.deletes)
+	})
+
+	t.Run("non-exclusive", func(t *testing.T) {
+		// given
+		owned := map[github.CIDR]github.IPAllowListEntryParameters{
+			"1.1.1.1/32": {},
+			"2.2.2.2/32": {},
+			"4.4.4.4/32": {},
+		}
+
+		// when
+		changes, err := computeIPAllowListChanges(desired, owned, current)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, []github.IPAllowListEntryParameters{desired["3.3.3.3/32"]}, changes.creates)
+		assert.Equal(t, map[string]github.IPAllowListEntryParameters{"renamed": desired["2.2.2.2/32"]}, changes.updates)
+		assert.ElementsMatch(t, []string{"duplicate", "previously-managed"}, changes.deletes)
+	})
+}
+
+func TestApplyIPAllowListChangesIgnoresAlreadyDeletedEntries(t *testing.T) {
+	// given
+	gitHubGraphQLAPIMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"d0": null, "c0": null}, "errors": [
+			{"type": "NOT_FOUND", "path": ["d0"], "message": "Could not resolve to a node with the global id of 'IALE_deleted'."},
+			{"type": "UNPROCESSABLE", "path": ["c0"], "message": "Allow list value is invalid"}
+		]}`))
+	}))
+	defer gitHubGraphQLAPIMock.Close()
+	client := &apiClient{github: github.NewGitHubClient(http.DefaultClient, github.WithGraphQLAPIURL(gitHubGraphQLAPIMock.URL))}
+	changes := ipAllowListChanges{
+		creates: []github.IPAllowListEntryParameters{{Value: "invalid"}},
+		deletes: []string{"IALE_deleted"},
+	}
+
+	// when
+	err := applyIPAllowListChanges(context.TODO(), client, "some-owner-id", changes)
+
+	// then
+	assert.ErrorIs(t, err, github.ErrValidation)
+	assert.NotErrorIs(t, err, github.ErrNotFound)
+}
+
+func TestInheritedEntriesCoverageWarnings(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceGitHubIPAllowList().Schema, map[string]any{
+		entryKey: []any{
+			map[string]any{allowListValueKey: "10.1.2.3", isActiveKey: true},
+			map[string]any{allowListValueKey: "192.0.2.0/24", isActiveKey: true},
+		},
+	})
+	configured := d.Get(entryKey).(*schema.Set)
+
+	t.Run("without inherited entries", func(t *testing.T) {
+		// when
+		diags := inheritedEntriesCoverageWarnings(&github.InheritedIPAllowListEntries{}, configured)
+
+		// then
+		assert.Empty(t, diags)
+	})
+
+	t.Run("with unresolved inherited entries", func(t *testing.T) {
+		// when
+		diags := inheritedEntriesCoverageWarnings(&github.InheritedIPAllowListEntries{Count: 2}, configured)
+
+		// then
+		assert.Len(t, diags, 1)
+		assert.Equal(t, diag.Warning, diags[0].Severity)
+		assert.Contains(t, diags[0].Detail, "inherits 2 entries")
+	})
+
+	t.Run("with covering inherited entries", func(t *testing.T) {
+		// given
+		inherited := &github.InheritedIPAllowListEntries{
+			Count:      2,
+			Enterprise: "some enterprise",
+			Entries: []*github.IPAllowListEntry{
+				{ID: "inactive", AllowListValue: "192.0.0.0/8", Name: "inactive", IsActive: false},
+				{ID: "covering", AllowListValue: "10.0.0.0/8", Name: "corporate network", IsActive: true},
+			},
+		}
+
+		// when
+		diags := inheritedEntriesCoverageWarnings(inherited, configured)
+
+		// then
+		assert.Len(t, diags, 1)
+		assert.Equal(t, diag.Warning, diags[0].Severity)
+		assert.Equal(t, `allow_list_value "10.1.2.3" is already covered by "10.0.0.0/8" (corporate network) of the enterprise some enterprise.`, diags[0].Detail)
+	})
+}